@@ -0,0 +1,2 @@
+# Assets are imported by source id and origin path
+terraform import imgix_asset.hero 601430223753592c4e822e2c/images/hero.jpg
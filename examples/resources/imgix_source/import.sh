@@ -0,0 +1,9 @@
+# Sources can be imported by id
+terraform import imgix_source.this 601430223753592c4e822e2c
+
+# or looked up by name or by one of their imgix subdomains
+terraform import imgix_source.this name:source1
+terraform import imgix_source.this subdomain:example-1
+
+# S3 secret key is never returned by the API, so it has to be provided during import
+IMGIX_IMPORT_S3_SECRET_KEY=secret terraform import imgix_source.this name:source1
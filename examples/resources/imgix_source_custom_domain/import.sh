@@ -0,0 +1,2 @@
+# Custom domains are imported by <source_id>/<domain>
+terraform import imgix_source_custom_domain.this 601430223753592c4e822e2c/images.example.com
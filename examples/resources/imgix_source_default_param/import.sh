@@ -0,0 +1,2 @@
+# Default parameters are imported by <source_id>/<key>
+terraform import imgix_source_default_param.this 601430223753592c4e822e2c/auto
@@ -0,0 +1,210 @@
+package imgix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// IMGIX_CASSETTE switches the client to replay API interactions from the given file
+	// without network access, or to record them into it when IMGIX_CASSETTE_MODE is record
+	cassetteEnv     = "IMGIX_CASSETTE"
+	cassetteModeEnv = "IMGIX_CASSETTE_MODE"
+
+	CassetteModeRecord = "record"
+	CassetteModeReplay = "replay"
+)
+
+type cassette struct {
+	Interactions []*cassetteInteraction `json:"interactions"`
+}
+
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+
+	replayed bool
+}
+
+type cassetteRequest struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+}
+
+type cassetteResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers"`
+	Body    string      `json:"body"`
+}
+
+// cassetteTransport records interactions with the API into a cassette file or replays them from it.
+// Requests are matched on method, path with query and normalized JSON body.
+type cassetteTransport struct {
+	mode      string
+	path      string
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette *cassette
+}
+
+func newCassetteTransport(mode, path string, transport http.RoundTripper) (*cassetteTransport, error) {
+	if mode == "" {
+		mode = CassetteModeReplay
+	}
+
+	t := &cassetteTransport{
+		mode:      mode,
+		path:      path,
+		transport: transport,
+		cassette:  &cassette{},
+	}
+
+	switch mode {
+	case CassetteModeRecord:
+		return t, nil
+	case CassetteModeReplay:
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading cassette: %s", err.Error())
+		}
+
+		if err = json.Unmarshal(raw, t.cassette); err != nil {
+			return nil, fmt.Errorf("Error parsing cassette %s: %s", path, err.Error())
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("Invalid %s: %s", cassetteModeEnv, mode)
+	}
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	request := cassetteRequest{
+		Method:  req.Method,
+		Path:    requestPath(req),
+		Headers: redactHeaders(req.Header),
+		Body:    normalizeCassetteBody(reqBody),
+	}
+
+	if t.mode == CassetteModeReplay {
+		return t.replay(req, request)
+	}
+	return t.record(req, request)
+}
+
+func (t *cassetteTransport) record(req *http.Request, request cassetteRequest) (*http.Response, error) {
+	res, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cassette.Interactions = append(t.cassette.Interactions, &cassetteInteraction{
+		Request: request,
+		Response: cassetteResponse{
+			Status:  res.StatusCode,
+			Headers: redactHeaders(res.Header),
+			Body:    string(redactJsonBody(resBody)),
+		},
+	})
+
+	return res, t.save()
+}
+
+func (t *cassetteTransport) save() error {
+	raw, err := json.MarshalIndent(t.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(t.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.path, append(raw, '\n'), 0644)
+}
+
+func (t *cassetteTransport) replay(req *http.Request, request cassetteRequest) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// interactions are replayed in recorded order, the last match is repeated
+	// once all are used up, e.g. when polling for a deployment status
+	var match *cassetteInteraction
+	for _, i := range t.cassette.Interactions {
+		if i.Request.Method != request.Method || i.Request.Path != request.Path || i.Request.Body != request.Body {
+			continue
+		}
+
+		match = i
+		if !i.replayed {
+			break
+		}
+	}
+
+	if match == nil {
+		return nil, fmt.Errorf("No interaction recorded in %s for %s %s", t.path, request.Method, request.Path)
+	}
+
+	match.replayed = true
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Response.Status, http.StatusText(match.Response.Status)),
+		StatusCode:    match.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        match.Response.Headers.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewBufferString(match.Response.Body)),
+		ContentLength: int64(len(match.Response.Body)),
+		Request:       req,
+	}, nil
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func requestPath(req *http.Request) string {
+	path := req.URL.Path
+	if query := req.URL.Query(); len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return path
+}
+
+// normalizeCassetteBody re-encodes JSON bodies with sorted keys and without credentials
+func normalizeCassetteBody(body []byte) string {
+	return string(redactJsonBody(body))
+}
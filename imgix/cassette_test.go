@@ -0,0 +1,160 @@
+package imgix
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// prepareCassetteTest returns a client replaying testdata/cassettes/<name>.json. With IMGIX_CASSETTE_MODE=record
+// the cassette is recorded again against the real API using the key from IMGIX_API_KEY.
+func prepareCassetteTest(t *testing.T, name string) *client {
+	mode := os.Getenv(cassetteModeEnv)
+	path := filepath.Join("testdata", "cassettes", name+".json")
+	transport, err := newCassetteTransport(mode, path, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiKey := testApiToken
+	if mode == CassetteModeRecord {
+		apiKey = os.Getenv("IMGIX_API_KEY")
+	}
+
+	return &client{
+		apiKey:     apiKey,
+		apiUrl:     apiUrl,
+		httpClient: &http.Client{Transport: transport},
+	}
+}
+
+func TestReplayingSourceCassette(t *testing.T) {
+	c := prepareCassetteTest(t, "get_source")
+
+	s, err := c.getSourceById(context.Background(), testSourceId)
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	if *s.Id != testSourceId || s.Attributes.Name != "source1" {
+		t.Error("replayed source doesnt match expected")
+	}
+
+	sources, err := c.listSources(context.Background(), map[string]string{"name": "source1"})
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	if len(sources) != 1 || *sources[0].Id != testSourceId {
+		t.Error("replayed source list doesnt match expected")
+	}
+}
+
+func TestRecordingCassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	ts := startMockServer(t)
+	transport, err := newCassetteTransport(CassetteModeRecord, path, http.DefaultTransport)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &client{apiKey: testApiToken, apiUrl: ts.URL, httpClient: &http.Client{Transport: transport}}
+	recorded, err := c.getSourceById(context.Background(), testSourceId)
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	update := &Source{Id: String(testSourceId)}
+	update.Attributes.Deployment.S3SecretKey = String("very-secret")
+	if _, err = c.updateSource(context.Background(), update); err != nil {
+		t.Fatalf("update error should be nil: %s", err)
+	}
+	ts.Close()
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, secret := range []string{"Bearer " + testApiToken, "very-secret"} {
+		if bytes.Contains(raw, []byte(secret)) {
+			t.Errorf("cassette contains %s", secret)
+		}
+	}
+
+	transport, err = newCassetteTransport(CassetteModeReplay, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.httpClient = &http.Client{Transport: transport}
+	replayed, err := c.getSourceById(context.Background(), testSourceId)
+	if err != nil {
+		t.Fatalf("replay error should be nil: %s", err)
+	}
+
+	if !reflect.DeepEqual(recorded, replayed) {
+		t.Error("replayed source doesnt match recorded one")
+	}
+
+	update.Attributes.Deployment.S3SecretKey = String("another-secret")
+	if _, err = c.updateSource(context.Background(), update); err != nil {
+		t.Errorf("update with redacted secret should match recorded request: %s", err)
+	}
+}
+
+func TestReplayingCassetteMatchesNormalizedBody(t *testing.T) {
+	transport := &cassetteTransport{
+		mode: CassetteModeReplay,
+		path: "memory",
+		cassette: &cassette{
+			Interactions: []*cassetteInteraction{
+				{
+					Request:  cassetteRequest{Method: http.MethodPost, Path: "/api/v1/sources", Body: `{"a":1,"b":{"c":2}}`},
+					Response: cassetteResponse{Status: http.StatusCreated, Body: "first"},
+				},
+				{
+					Request:  cassetteRequest{Method: http.MethodPost, Path: "/api/v1/sources", Body: `{"a":1,"b":{"c":2}}`},
+					Response: cassetteResponse{Status: http.StatusCreated, Body: "second"},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		body     string
+		expected string
+	}{
+		{"{\n  \"b\": {\"c\": 2},\n  \"a\": 1\n}", "first"},
+		{`{"b":{"c":2},"a":1}`, "second"},
+		{`{"a":1,"b":{"c":2}}`, "second"},
+		{`{"a":2}`, ""},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest(http.MethodPost, "https://api.imgix.com/api/v1/sources", strings.NewReader(c.body))
+		res, err := transport.RoundTrip(req)
+		if c.expected == "" {
+			if err == nil {
+				t.Errorf("body %s should not match any interaction", c.body)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("body %s should match an interaction: %s", c.body, err)
+			continue
+		}
+
+		body, _ := ioutil.ReadAll(res.Body)
+		if string(body) != c.expected {
+			t.Errorf("body %s replayed %s instead of %s", c.body, body, c.expected)
+		}
+	}
+}
@@ -2,12 +2,23 @@ package imgix
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"io"
 	"io/ioutil"
+	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -16,6 +27,9 @@ const (
 	TypeSource = "sources"
 
 	InvalidAwsAccessKeyError = "aws_access_key"
+
+	maxRetries    = 3
+	maxRetryDelay = 30 * time.Second
 )
 
 var (
@@ -23,8 +37,39 @@ var (
 )
 
 type client struct {
-	apiKey string
-	apiUrl string
+	apiKey     string
+	apiUrl     string
+	httpClient *http.Client
+	retryDelay time.Duration
+
+	// authentication is set when the API key is validated during provider configuration
+	authentication *Authentication
+
+	// sourceDefaults of the provider configuration are merged into every imgix_source deployment
+	sourceDefaults *sourceDefaults
+
+	// sourceLocks are held during read-modify-write updates of a source, keyed by source id
+	sourceLocks *mutexKV
+
+	// dnsResolver checks custom domain records, see newDnsResolver
+	dnsResolver *net.Resolver
+
+	// preflightS3Endpoint replaces the AWS endpoint in preflight checks of s3 origins, see newS3Origin. Only set by tests.
+	preflightS3Endpoint string
+
+	// originHttpClient sends preflight requests to origins, redirects aren't followed as they would break signatures
+	originHttpClient *http.Client
+
+	// healthCheckHttpClient fetches health check probes through the hosts serving a source
+	healthCheckHttpClient *http.Client
+}
+
+// Authentication describes the API key used for requests, as returned in meta.authentication
+type Authentication struct {
+	Authorized bool   `json:"authorized"`
+	Mode       string `json:"mode"`
+	ModeTitle  string `json:"modeTitle"`
+	Tag        string `json:"tag"`
 }
 
 type sourceAttributes struct {
@@ -57,6 +102,11 @@ type sourceDeployment struct {
 	S3Bucket    *string `json:"s3_bucket"`
 	S3Prefix    *string `json:"s3_prefix"`
 
+	// S3-compatible storage only
+	S3Endpoint  *string `json:"s3_endpoint,omitempty"`
+	S3Region    *string `json:"s3_region,omitempty"`
+	S3PathStyle *bool   `json:"s3_path_style,omitempty"`
+
 	SecureUrlEnabled *bool  `json:"secure_url_enabled"`
 	Type             string `json:"type"`
 }
@@ -82,6 +132,35 @@ type SourceRequest struct {
 	Data *Source `json:"data"`
 }
 
+type SourceListResponse struct {
+	Data  []*Source `json:"data"`
+	Links struct {
+		// Next is the URL of the next page, it is missing on the last page
+		Next *string `json:"next"`
+	} `json:"links"`
+}
+
+type sourceDeploymentHistoryAttributes struct {
+	Annotation       *string `json:"annotation"`
+	DateCreated      *int    `json:"date_created"`
+	DateDeployed     *int    `json:"date_deployed"`
+	DeploymentStatus *string `json:"deployment_status"`
+
+	Deployment sourceDeployment `json:"deployment"`
+}
+
+// SourceDeploymentHistory is a past or current deployment of a source
+type SourceDeploymentHistory struct {
+	Id   *string `json:"id,omitempty"`
+	Type *string `json:"type,omitempty"`
+
+	Attributes sourceDeploymentHistoryAttributes `json:"attributes"`
+}
+
+type SourceDeploymentListResponse struct {
+	Data []*SourceDeploymentHistory `json:"data"`
+}
+
 func NewClient(config Config) (*client, error) {
 	if config.AccessKey == "" {
 		return nil, missingAccessKeyError
@@ -91,14 +170,61 @@ func NewClient(config Config) (*client, error) {
 		config.ApiBaseUrl = apiUrl
 	}
 
+	transport := http.DefaultTransport
+	if path := os.Getenv(cassetteEnv); path != "" {
+		cassetteTransport, err := newCassetteTransport(os.Getenv(cassetteModeEnv), path, transport)
+		if err != nil {
+			return nil, err
+		}
+		transport = cassetteTransport
+	}
+
 	return &client{
-		apiKey: config.AccessKey,
-		apiUrl: config.ApiBaseUrl,
+		apiKey:      config.AccessKey,
+		apiUrl:      config.ApiBaseUrl,
+		httpClient:  &http.Client{Transport: transport},
+		retryDelay:  time.Second,
+		sourceLocks: newMutexKV(),
+		originHttpClient: &http.Client{
+			Timeout: time.Minute,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		healthCheckHttpClient: &http.Client{Timeout: 30 * time.Second},
 	}, nil
 }
 
-func (c *client) getSourceById(id string) (*Source, error) {
-	res, err := c.doRequest("GET", "/api/v1/sources/"+id, nil)
+// getAuthentication makes a small authenticated request and returns the API key details from its meta
+func (c *client) getAuthentication(ctx context.Context) (*Authentication, error) {
+	res, err := c.doRequest(ctx, http.MethodGet, "/api/v1/sources?page[size]=1", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, serializeApiError(res)
+	}
+
+	body := &struct {
+		Meta struct {
+			Authentication *Authentication `json:"authentication"`
+		} `json:"meta"`
+	}{}
+	if err = json.NewDecoder(res.Body).Decode(body); err != nil {
+		return nil, err
+	}
+
+	if body.Meta.Authentication == nil {
+		return nil, errors.New("Response is missing meta.authentication")
+	}
+	return body.Meta.Authentication, nil
+}
+
+func (c *client) getSourceById(ctx context.Context, id string) (*Source, error) {
+	res, err := c.doRequest(ctx, "GET", "/api/v1/sources/"+id, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -112,21 +238,103 @@ func (c *client) getSourceById(id string) (*Source, error) {
 	return source.Data, nil
 }
 
-func (c *client) createSource(source *Source) (*Source, error) {
-	res, err := c.sendSourceRequest("/api/v1/sources", http.MethodPost, source)
+func (c *client) listSources(ctx context.Context, filters map[string]string) ([]*Source, error) {
+	query := url.Values{}
+	for k, v := range filters {
+		query.Set(fmt.Sprintf("filter[%s]", k), v)
+	}
+
+	path := "/api/v1/sources"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var sources []*Source
+	seen := map[string]bool{}
+	for {
+		page, err := c.getSourcePage(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, page.Data...)
+		if page.Links.Next == nil || *page.Links.Next == "" {
+			return sources, nil
+		}
+
+		// links are absolute, requests are always sent to the configured API url
+		next, err := url.Parse(*page.Links.Next)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing next page link %s: %s", *page.Links.Next, err.Error())
+		}
+
+		path = next.RequestURI()
+		if seen[path] {
+			return sources, nil
+		}
+		seen[path] = true
+	}
+}
+
+func (c *client) getSourcePage(ctx context.Context, path string) (*SourceListResponse, error) {
+	res, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, serializeApiError(res)
+	}
+
+	page := &SourceListResponse{}
+	if err = json.NewDecoder(res.Body).Decode(page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// listSourceDeployments returns all deployments of a source, newest first
+func (c *client) listSourceDeployments(ctx context.Context, sourceId string) ([]*SourceDeploymentHistory, error) {
+	res, err := c.doRequest(ctx, http.MethodGet, "/api/v1/sources/"+url.PathEscape(sourceId)+"/deployments", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, serializeApiError(res)
+	}
+
+	deployments := &SourceDeploymentListResponse{}
+	if err = json.NewDecoder(res.Body).Decode(deployments); err != nil {
+		return nil, err
+	}
+	return deployments.Data, nil
+}
+
+func (c *client) createSource(ctx context.Context, source *Source) (*Source, error) {
+	res, err := c.sendSourceRequest(ctx, "/api/v1/sources", http.MethodPost, source)
 	if err != nil {
 		return nil, err
 	} else if res.StatusCode != http.StatusCreated {
 		return nil, serializeApiError(res)
 	}
 
-	newSource := &Source{}
-	_ = json.NewDecoder(res.Body).Decode(newSource)
-	return newSource, nil
+	defer res.Body.Close()
+
+	newSource := &SourceRequest{}
+	if err = json.NewDecoder(res.Body).Decode(newSource); err != nil {
+		return nil, err
+	}
+	return newSource.Data, nil
 }
 
-func (c *client) updateSource(source *Source) (*Source, error) {
+func (c *client) updateSource(ctx context.Context, source *Source) (*Source, error) {
 	res, err := c.sendSourceRequest(
+		ctx,
 		"/api/v1/sources/"+*source.Id,
 		http.MethodPatch,
 		source,
@@ -143,14 +351,14 @@ func (c *client) updateSource(source *Source) (*Source, error) {
 	return source, nil
 }
 
-func (c *client) sendSourceRequest(endpoint, method string, source *Source) (*http.Response, error) {
+func (c *client) sendSourceRequest(ctx context.Context, endpoint, method string, source *Source) (*http.Response, error) {
 	d := SourceRequest{Data: source}
 	b, err := json.Marshal(d)
 	if err != nil {
 		return nil, errors.New(fmt.Sprintf("Error marshalling data: %s", err.Error()))
 	}
 
-	res, err := c.doRequest(method, endpoint, bytes.NewBuffer(b))
+	res, err := c.doRequest(ctx, method, endpoint, bytes.NewBuffer(b))
 	if err != nil {
 		return res, errors.New(fmt.Sprintf("Error sending request to Imgix API: %s", err))
 	}
@@ -158,23 +366,158 @@ func (c *client) sendSourceRequest(endpoint, method string, source *Source) (*ht
 	return res, nil
 }
 
-func (c *client) deleteSource(source *Source) error {
+func (c *client) deleteSource(ctx context.Context, source *Source) error {
 	source.Attributes.Enabled = Bool(false)
-	_, err := c.updateSource(source)
+	_, err := c.updateSource(ctx, source)
 	return err
 }
 
-func (c *client) doRequest(method, path string, body io.Reader) (*http.Response, error) {
-	url := c.apiUrl + path
-	req, err := http.NewRequest(method, url, body)
+// patchSourceDeployment sends only the given deployment attributes, other deployment settings are kept.
+// Callers hold the source lock, so values read before the update aren't overwritten by a concurrent change.
+func (c *client) patchSourceDeployment(ctx context.Context, id string, deployment map[string]interface{}) error {
+	return c.patchSource(ctx, id, map[string]interface{}{"deployment": deployment})
+}
+
+// disableSource stops a source from serving without sending its deployment
+func (c *client) disableSource(ctx context.Context, id string) error {
+	return c.patchSource(ctx, id, map[string]interface{}{"enabled": false})
+}
+
+func (c *client) patchSource(ctx context.Context, id string, attributes map[string]interface{}) error {
+	patch := map[string]interface{}{
+		"data": map[string]interface{}{
+			"id":         id,
+			"type":       TypeSource,
+			"attributes": attributes,
+		},
+	}
+
+	b, err := json.Marshal(patch)
 	if err != nil {
-		return nil, err
+		return errors.New(fmt.Sprintf("Error marshalling data: %s", err.Error()))
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	res, err := c.doRequest(ctx, http.MethodPatch, "/api/v1/sources/"+id, bytes.NewBuffer(b))
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error sending request to Imgix API: %s", err))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return serializeApiError(res)
+	}
+	return nil
+}
 
-	return http.DefaultClient.Do(req)
+// uploadObject stores content in the origin of a source which allows uploads
+func (c *client) uploadObject(ctx context.Context, sourceId, originPath, contentType string, content []byte) error {
+	endpoint := "/api/v1/sources/upload/" + url.PathEscape(sourceId) + escapeOriginPath(originPath)
+	res, err := c.doRequestWithContentType(ctx, http.MethodPost, endpoint, contentType, bytes.NewReader(content))
+	if err != nil {
+		return errors.New(fmt.Sprintf("Error sending request to Imgix API: %s", err))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return serializeApiError(res)
+	}
+	return nil
+}
+
+func (c *client) doRequest(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	return c.doRequestWithContentType(ctx, method, path, "application/json", body)
+}
+
+func (c *client) doRequestWithContentType(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = ioutil.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, span := startSpan(
+		ctx,
+		"imgix.api_request",
+		semconv.HTTPRequestMethodKey.String(method),
+		semconv.URLPath(strings.SplitN(path, "?", 2)[0]),
+		attribute.String("imgix.resource_id", resourceIdFromPath(path)),
+	)
+
+	start := time.Now()
+	for retries := 0; ; retries++ {
+		req, err := http.NewRequestWithContext(ctx, method, c.apiUrl+path, bytes.NewReader(payload))
+		if err != nil {
+			endSpan(span, err)
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		req.Header.Set("Content-Type", contentType)
+
+		res, err := c.httpClient.Do(req)
+		if err == nil && retries < maxRetries && isRetryable(method, res.StatusCode) {
+			delay := retryDelay(res, c.retryDelay, retries)
+			log.Printf(
+				"[DEBUG] imgix API request will be retried: method=%s path=%s status=%d delay=%s retries=%d",
+				method,
+				path,
+				res.StatusCode,
+				delay,
+				retries,
+			)
+			_, _ = io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+
+			select {
+			case <-ctx.Done():
+				endSpan(span, ctx.Err())
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			continue
+		}
+
+		logApiRequest(req, payload, res, err, time.Since(start), retries)
+
+		span.SetAttributes(semconv.HTTPRequestResendCount(retries))
+		if res != nil {
+			span.SetAttributes(
+				semconv.HTTPResponseStatusCode(res.StatusCode),
+				attribute.String("imgix.request_id", res.Header.Get("X-Request-Id")),
+			)
+			if res.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, http.StatusText(res.StatusCode))
+			}
+		}
+		endSpan(span, err)
+		return res, err
+	}
+}
+
+func isRetryable(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		// creating a source isn't idempotent, it could have been created before the error
+		return method != http.MethodPost
+	}
+	return false
+}
+
+func retryDelay(res *http.Response, base time.Duration, retries int) time.Duration {
+	if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		delay := time.Duration(seconds) * time.Second
+		if delay > maxRetryDelay {
+			return maxRetryDelay
+		}
+		return delay
+	}
+	return base << uint(retries)
 }
 
 func serializeApiError(res *http.Response) error {
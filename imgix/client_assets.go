@@ -0,0 +1,207 @@
+package imgix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const TypeAsset = "assets"
+
+type assetAttributes struct {
+	Categories   []string               `json:"categories"`
+	ContentType  *string                `json:"content_type,omitempty"`
+	CustomFields map[string]interface{} `json:"custom_fields"`
+	DateCreated  *int                   `json:"date_created,omitempty"`
+	DateModified *int                   `json:"date_modified,omitempty"`
+	Description  *string                `json:"description"`
+	Etag         *string                `json:"etag,omitempty"`
+	FileSize     *int                   `json:"file_size,omitempty"`
+	MediaHeight  *int                   `json:"media_height,omitempty"`
+	MediaWidth   *int                   `json:"media_width,omitempty"`
+	OriginPath   string                 `json:"origin_path,omitempty"`
+	SourceId     string                 `json:"source_id,omitempty"`
+	Status       *string                `json:"status,omitempty"`
+	Tags         []string               `json:"tags"`
+}
+
+type Asset struct {
+	Id   *string `json:"id,omitempty"`
+	Type *string `json:"type,omitempty"`
+
+	Attributes assetAttributes `json:"attributes"`
+}
+
+// MarshalJSON only sends attributes which can be changed through the API
+func (a Asset) MarshalJSON() ([]byte, error) {
+	type alias Asset
+	var al = alias(a)
+	al.Attributes.ContentType = nil
+	al.Attributes.DateCreated = nil
+	al.Attributes.DateModified = nil
+	al.Attributes.Etag = nil
+	al.Attributes.FileSize = nil
+	al.Attributes.MediaHeight = nil
+	al.Attributes.MediaWidth = nil
+	al.Attributes.OriginPath = ""
+	al.Attributes.SourceId = ""
+	al.Attributes.Status = nil
+	return json.Marshal(al)
+}
+
+type AssetRequest struct {
+	Data *Asset `json:"data"`
+}
+
+type AssetListResponse struct {
+	Data []*Asset `json:"data"`
+	Meta struct {
+		Cursor struct {
+			HasMore bool   `json:"hasMore"`
+			Next    string `json:"next"`
+		} `json:"cursor"`
+	} `json:"meta"`
+}
+
+// getAsset returns nil without an error when the asset doesn't exist
+func (c *client) getAsset(ctx context.Context, sourceId, originPath string) (*Asset, error) {
+	res, err := c.doRequest(ctx, http.MethodGet, assetEndpoint(sourceId, originPath), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	} else if res.StatusCode != http.StatusOK {
+		return nil, serializeApiError(res)
+	}
+
+	asset := &AssetRequest{}
+	if err = json.NewDecoder(res.Body).Decode(asset); err != nil {
+		return nil, err
+	}
+	return asset.Data, nil
+}
+
+// listAssets returns assets of a source matching the filters, following all result pages.
+// Filters are sent as filter[<name>], a name may end with an operator, e.g. date_created[gte].
+func (c *client) listAssets(ctx context.Context, sourceId string, filters map[string]string, pageSize int) ([]*Asset, error) {
+	var assets []*Asset
+	seen := map[string]bool{}
+	cursor := ""
+
+	for {
+		query := url.Values{}
+		for k, v := range filters {
+			name, operator := k, ""
+			if i := strings.Index(k, "["); i >= 0 {
+				name, operator = k[:i], k[i:]
+			}
+			query.Set(fmt.Sprintf("filter[%s]%s", name, operator), v)
+		}
+		query.Set("page[limit]", strconv.Itoa(pageSize))
+		if cursor != "" {
+			query.Set("page[cursor]", cursor)
+		}
+
+		path := "/api/v1/assets/" + url.PathEscape(sourceId) + "?" + query.Encode()
+		page, err := c.getAssetPage(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+
+		assets = append(assets, page.Data...)
+
+		cursor = page.Meta.Cursor.Next
+		if !page.Meta.Cursor.HasMore || cursor == "" || seen[cursor] {
+			return assets, nil
+		}
+		seen[cursor] = true
+	}
+}
+
+func (c *client) getAssetPage(ctx context.Context, path string) (*AssetListResponse, error) {
+	res, err := c.doRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, serializeApiError(res)
+	}
+
+	page := &AssetListResponse{}
+	if err = json.NewDecoder(res.Body).Decode(page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (c *client) updateAsset(ctx context.Context, asset *Asset) (*Asset, error) {
+	b, err := json.Marshal(AssetRequest{Data: asset})
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Error marshalling data: %s", err.Error()))
+	}
+
+	endpoint := assetEndpoint(asset.Attributes.SourceId, asset.Attributes.OriginPath)
+	res, err := c.doRequest(ctx, http.MethodPatch, endpoint, bytes.NewReader(b))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Error sending request to Imgix API: %s", err))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, serializeApiError(res)
+	}
+
+	updated := &AssetRequest{}
+	if err = json.NewDecoder(res.Body).Decode(updated); err != nil {
+		return nil, err
+	}
+	return updated.Data, nil
+}
+
+// refreshAsset asks imgix to fetch the asset from the origin again and re-index its metadata
+func (c *client) refreshAsset(ctx context.Context, sourceId, originPath string) (*Asset, error) {
+	endpoint := "/api/v1/assets/refresh/" + url.PathEscape(sourceId) + escapeOriginPath(originPath)
+	res, err := c.doRequest(ctx, http.MethodPost, endpoint, nil)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Error sending request to Imgix API: %s", err))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusAccepted {
+		return nil, serializeApiError(res)
+	}
+
+	refreshed := &AssetRequest{}
+	if err = json.NewDecoder(res.Body).Decode(refreshed); err != nil {
+		return nil, err
+	}
+	return refreshed.Data, nil
+}
+
+func assetEndpoint(sourceId, originPath string) string {
+	return "/api/v1/assets/" + url.PathEscape(sourceId) + escapeOriginPath(originPath)
+}
+
+// escapeOriginPath escapes every segment of the path and makes sure it starts with a slash
+func escapeOriginPath(originPath string) string {
+	segments := strings.Split(strings.TrimPrefix(originPath, "/"), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,158 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func seedTestAsset(t *testing.T) (*client, string) {
+	c, api := prepareFakeApiTest(t)
+	sourceId := api.SeedSource(map[string]interface{}{
+		"name": "assets",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"assets"},
+		},
+	})
+	api.SeedAsset(sourceId, "/images/hero image.jpg", map[string]interface{}{
+		"content_type":  "image/jpeg",
+		"custom_fields": map[string]interface{}{"owner": "cms"},
+	})
+	return c, sourceId
+}
+
+func TestGettingAsset(t *testing.T) {
+	c, sourceId := seedTestAsset(t)
+
+	asset, err := c.getAsset(context.Background(), sourceId, "/images/hero image.jpg")
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	if *asset.Id != sourceId+"/images/hero image.jpg" || *asset.Attributes.ContentType != "image/jpeg" {
+		t.Error("asset doesnt match expected")
+	}
+
+	missing, err := c.getAsset(context.Background(), sourceId, "/images/missing.jpg")
+	if err != nil || missing != nil {
+		t.Errorf("missing asset should be nil without error, got %v, %v", missing, err)
+	}
+}
+
+func TestUpdatingAsset(t *testing.T) {
+	c, sourceId := seedTestAsset(t)
+
+	asset := &Asset{Id: String(sourceId + "/images/hero image.jpg"), Type: String(TypeAsset)}
+	asset.Attributes.SourceId = sourceId
+	asset.Attributes.OriginPath = "/images/hero image.jpg"
+	asset.Attributes.Description = String("Hero image")
+	asset.Attributes.Tags = []string{"hero"}
+	asset.Attributes.Categories = []string{"homepage"}
+	asset.Attributes.CustomFields = map[string]interface{}{"owner": "marketing"}
+
+	updated, err := c.updateAsset(context.Background(), asset)
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	if *updated.Attributes.Description != "Hero image" ||
+		!reflect.DeepEqual(updated.Attributes.Tags, []string{"hero"}) ||
+		!reflect.DeepEqual(updated.Attributes.Categories, []string{"homepage"}) ||
+		updated.Attributes.CustomFields["owner"] != "marketing" {
+		t.Errorf("asset was not updated: %+v", updated.Attributes)
+	}
+}
+
+func TestEscapingAssetEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"/image.jpg":         "/api/v1/assets/abc/image.jpg",
+		"image.jpg":          "/api/v1/assets/abc/image.jpg",
+		"/a b/c?d.jpg":       "/api/v1/assets/abc/a%20b/c%3Fd.jpg",
+		"/nested/path/x.png": "/api/v1/assets/abc/nested/path/x.png",
+	}
+
+	for path, expected := range cases {
+		if endpoint := assetEndpoint("abc", path); endpoint != expected {
+			t.Errorf("endpoint for %s should be %s, got %s", path, expected, endpoint)
+		}
+	}
+}
+
+func TestListingAssetsFollowsPages(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	sourceId := api.SeedSource(map[string]interface{}{
+		"name": "assets",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"assets"},
+		},
+	})
+	for i := 0; i < 5; i++ {
+		api.SeedAsset(sourceId, fmt.Sprintf("/images/%d.jpg", i), map[string]interface{}{})
+	}
+	api.SeedAsset(sourceId, "/videos/intro.mp4", map[string]interface{}{"content_type": "video/mp4"})
+
+	assets, err := c.listAssets(context.Background(), sourceId, map[string]string{"origin_path": "/images/"}, 2)
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	if len(assets) != 5 {
+		t.Fatalf("expected 5 assets, got %d", len(assets))
+	}
+
+	for i, a := range assets {
+		if a.Attributes.OriginPath != fmt.Sprintf("/images/%d.jpg", i) || a.Attributes.Etag == nil {
+			t.Errorf("unexpected asset %d: %+v", i, a.Attributes)
+		}
+	}
+}
+
+func TestListingAssetsWithDateFilters(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	sourceId := api.SeedSource(map[string]interface{}{
+		"name": "assets",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"assets"},
+		},
+	})
+	for i, created := range []int64{1600000000, 1610000000, 1620000000} {
+		api.SeedAsset(sourceId, fmt.Sprintf("/images/%d.jpg", i), map[string]interface{}{"date_created": created})
+	}
+
+	filter := assetFilter{createdAfter: unixTime(1610000000), createdBefore: unixTime(1620000000)}
+	assets, err := c.listAssets(context.Background(), sourceId, filter.apiFilters(), 10)
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	if len(assets) != 1 || assets[0].Attributes.OriginPath != "/images/1.jpg" {
+		t.Errorf("only the asset created in range should be listed by the API, got %d assets", len(assets))
+	}
+}
+
+func unixTime(seconds int64) *time.Time {
+	t := time.Unix(seconds, 0)
+	return &t
+}
+
+func TestRefreshingAsset(t *testing.T) {
+	c, sourceId := seedTestAsset(t)
+
+	asset, err := c.refreshAsset(context.Background(), sourceId, "/images/hero image.jpg")
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	if asset.Attributes.Status == nil {
+		t.Error("refreshed asset should have a status")
+	}
+
+	if _, err = c.refreshAsset(context.Background(), sourceId, "/images/missing.jpg"); err == nil {
+		t.Error("refreshing a missing asset should fail")
+	}
+}
@@ -0,0 +1,185 @@
+package imgix
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	ReportTypeUsage = "usage"
+
+	reportFormatCsv  = "csv"
+	reportFormatJson = "json"
+)
+
+type reportAttributes struct {
+	Format      string  `json:"format"`
+	PeriodEnd   string  `json:"period_end"`
+	PeriodStart string  `json:"period_start"`
+	ReportType  string  `json:"report_type"`
+	SourceId    *string `json:"source_id"`
+	Url         string  `json:"url"`
+}
+
+type Report struct {
+	Id   *string `json:"id,omitempty"`
+	Type *string `json:"type,omitempty"`
+
+	Attributes reportAttributes `json:"attributes"`
+}
+
+type ReportListResponse struct {
+	Data []*Report `json:"data"`
+}
+
+// reportRow is a single day of usage in a usage report
+type reportRow struct {
+	Date          string  `json:"date"`
+	SourceId      string  `json:"source_id"`
+	Bandwidth     int64   `json:"bandwidth"`
+	Requests      int64   `json:"requests"`
+	MasterImages  int64   `json:"master_images"`
+	CacheHitRatio float64 `json:"cache_hit_ratio"`
+}
+
+func (c *client) listReports(ctx context.Context, filters map[string]string) ([]*Report, error) {
+	query := url.Values{}
+	for k, v := range filters {
+		query.Set(fmt.Sprintf("filter[%s]", k), v)
+	}
+
+	res, err := c.doRequest(ctx, http.MethodGet, "/api/v1/reports?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, serializeApiError(res)
+	}
+
+	reports := &ReportListResponse{}
+	if err = json.NewDecoder(res.Body).Decode(reports); err != nil {
+		return nil, err
+	}
+	return reports.Data, nil
+}
+
+// downloadReport fetches and decodes the report file. Report urls are signed,
+// so the API key isn't sent along and the url isn't logged.
+func (c *client) downloadReport(ctx context.Context, report *Report) ([]reportRow, error) {
+	reportUrl, err := url.Parse(report.Attributes.Url)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid report url: %s", err.Error())
+	}
+
+	if !reportUrl.IsAbs() {
+		base, err := url.Parse(c.apiUrl)
+		if err != nil {
+			return nil, err
+		}
+		reportUrl = base.ResolveReference(reportUrl)
+	}
+
+	log.Printf("[DEBUG] Downloading imgix report %s from %s%s", StringValue(report.Id), reportUrl.Host, reportUrl.Path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reportUrl.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Error downloading report: %s", err))
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Error downloading report: status %d", res.StatusCode)
+	}
+
+	content, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	format := report.Attributes.Format
+	if format == "" && strings.Contains(res.Header.Get("Content-Type"), "json") {
+		format = reportFormatJson
+	}
+	return decodeReportRows(format, content)
+}
+
+// decodeReportRows decodes report payloads, csv files are expected to have a header row
+func decodeReportRows(format string, content []byte) ([]reportRow, error) {
+	if format == reportFormatJson {
+		var rows []reportRow
+		if err := json.Unmarshal(content, &rows); err != nil {
+			return nil, fmt.Errorf("Error decoding json report: %s", err.Error())
+		}
+		return rows, nil
+	}
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Error decoding csv report: %s", err.Error())
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	var rows []reportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("Error decoding csv report: %s", err.Error())
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := reportRow{Date: value("date"), SourceId: value("source_id")}
+		for column, target := range map[string]*int64{
+			"bandwidth":     &row.Bandwidth,
+			"requests":      &row.Requests,
+			"master_images": &row.MasterImages,
+		} {
+			if v := value(column); v != "" {
+				if *target, err = strconv.ParseInt(v, 10, 64); err != nil {
+					return nil, fmt.Errorf("Invalid %s on line %d of csv report: %s", column, line, v)
+				}
+			}
+		}
+
+		if v := value("cache_hit_ratio"); v != "" {
+			if row.CacheHitRatio, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("Invalid cache_hit_ratio on line %d of csv report: %s", line, v)
+			}
+		}
+
+		rows = append(rows, row)
+	}
+}
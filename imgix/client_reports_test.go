@@ -0,0 +1,78 @@
+package imgix
+
+import (
+	"context"
+	"reflect"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestDecodingReportRows(t *testing.T) {
+	expected := []reportRow{
+		{Date: "2021-02-01", SourceId: "abc", Bandwidth: 1024, Requests: 10, MasterImages: 2, CacheHitRatio: 0.5},
+	}
+
+	cases := map[string][]byte{
+		reportFormatCsv:  []byte("date,source_id,bandwidth,requests,master_images,cache_hit_ratio\n2021-02-01,abc,1024,10,2,0.5\n"),
+		reportFormatJson: []byte(`[{"date":"2021-02-01","source_id":"abc","bandwidth":1024,"requests":10,"master_images":2,"cache_hit_ratio":0.5}]`),
+	}
+
+	for format, content := range cases {
+		t.Run(format, func(t *testing.T) {
+			rows, err := decodeReportRows(format, content)
+			if err != nil {
+				t.Fatalf("decoding should not fail: %s", err)
+			}
+
+			if !reflect.DeepEqual(rows, expected) {
+				t.Errorf("rows don't match expected: %+v", rows)
+			}
+		})
+	}
+}
+
+func TestDecodingReportRowsWithReorderedColumns(t *testing.T) {
+	rows, err := decodeReportRows(reportFormatCsv, []byte("requests,date\n5,2021-02-01\n"))
+	if err != nil {
+		t.Fatalf("decoding should not fail: %s", err)
+	}
+
+	if len(rows) != 1 || rows[0].Requests != 5 || rows[0].Date != "2021-02-01" {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+
+	if _, err = decodeReportRows(reportFormatCsv, []byte("date,requests\n2021-02-01,many\n")); err == nil {
+		t.Error("decoding invalid numbers should fail")
+	}
+}
+
+func TestDownloadingReports(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	api.SeedReport("", "2021-02-01", "2021-02-28", "csv", []fakeimgix.UsageRow{
+		{Date: "2021-02-01", SourceId: "abc", Bandwidth: 1024, Requests: 10},
+	})
+
+	reports, err := c.listReports(context.Background(), map[string]string{"report_type": ReportTypeUsage, "period_start": "2021-02-01"})
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	if len(reports) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(reports))
+	}
+
+	rows, err := c.downloadReport(context.Background(), reports[0])
+	if err != nil {
+		t.Fatalf("download error should be nil: %s", err)
+	}
+
+	if len(rows) != 1 || rows[0].Bandwidth != 1024 {
+		t.Errorf("unexpected rows: %+v", rows)
+	}
+
+	// the id is only used for logging
+	reports[0].Id = nil
+	if _, err = c.downloadReport(context.Background(), reports[0]); err != nil {
+		t.Errorf("downloading report without id should work: %s", err)
+	}
+}
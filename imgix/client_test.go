@@ -1,16 +1,20 @@
 package imgix
 
 import (
+	"context"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"terraform-provider-imgix/internal/fakeimgix"
 	"testing"
 )
 
 const (
-	testSourceId       = "601430223753592c4e822e2c"
-	testSourceEndpoint = "/api/v1/sources/" + testSourceId
+	testSourceId        = "601430223753592c4e822e2c"
+	testSourcesEndpoint = "/api/v1/sources"
+	testSourceEndpoint  = testSourcesEndpoint + "/" + testSourceId
 
 	testApiToken = "abc"
 )
@@ -62,6 +66,17 @@ func startMockServer(t *testing.T) *httptest.Server {
 			return
 		}
 
+		if path == testSourcesEndpoint && req.Method == http.MethodGet {
+			rawJson, err := ioutil.ReadFile("./testdata/sample_sources.json")
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+			w.Write(rawJson)
+		}
+
 		if path == testSourceEndpoint {
 			rawJson, err := ioutil.ReadFile("./testdata/sample_source.json")
 			if err != nil {
@@ -95,9 +110,24 @@ func prepareHttpTest(t *testing.T) *client {
 	return c
 }
 
+func prepareFakeApiTest(t *testing.T, options ...fakeimgix.Option) (*client, *fakeimgix.Server) {
+	api := fakeimgix.New(options...)
+	t.Cleanup(api.Close)
+
+	c, err := NewClient(Config{
+		AccessKey:  fakeimgix.DefaultApiKey,
+		ApiBaseUrl: api.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.retryDelay = 0
+	return c, api
+}
+
 func TestGettingSourceById(t *testing.T) {
 	c := prepareHttpTest(t)
-	s, err := c.getSourceById(testSourceId)
+	s, err := c.getSourceById(context.Background(), testSourceId)
 	if err != nil {
 		t.Error("response error should be nil")
 		return
@@ -139,6 +169,50 @@ func TestGettingSourceById(t *testing.T) {
 	}
 }
 
+func TestListingSources(t *testing.T) {
+	c := prepareHttpTest(t)
+	sources, err := c.listSources(context.Background(), map[string]string{"name": "source2"})
+	if err != nil {
+		t.Error("response error should be nil")
+		return
+	}
+
+	if len(sources) != 2 {
+		t.Errorf("expected 2 sources, got %d", len(sources))
+		return
+	}
+
+	if *sources[1].Id != "601430223753592c4e822e2d" || sources[1].Attributes.Name != "source2" {
+		t.Error("second source doesnt match expected")
+	}
+}
+
+func TestListingSourcesFollowsPages(t *testing.T) {
+	c, api := prepareFakeApiTest(t, fakeimgix.WithPageSize(2))
+	for i := 0; i < 5; i++ {
+		api.SeedSource(map[string]interface{}{
+			"name": fmt.Sprintf("source%d", i%3),
+			"deployment": map[string]interface{}{
+				"type":             "webfolder",
+				"imgix_subdomains": []string{fmt.Sprintf("example-%d", i)},
+			},
+		})
+	}
+
+	sources, err := c.listSources(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+	if len(sources) != 5 {
+		t.Errorf("expected 5 sources on 3 pages, got %d", len(sources))
+	}
+
+	sources, err = c.listSources(context.Background(), map[string]string{"name": "source1"})
+	if err != nil || len(sources) != 2 {
+		t.Errorf("expected 2 filtered sources, got %d: %v", len(sources), err)
+	}
+}
+
 func TestDeletingSource(t *testing.T) {
 	c := prepareHttpTest(t)
 
@@ -149,7 +223,7 @@ func TestDeletingSource(t *testing.T) {
 		},
 	}
 
-	e := c.deleteSource(source)
+	e := c.deleteSource(context.Background(), source)
 	if e != nil {
 		t.Error("error should be nil when deleting source")
 	}
@@ -158,3 +232,47 @@ func TestDeletingSource(t *testing.T) {
 		t.Error("source should be disabled after deletion")
 	}
 }
+
+func TestListingSourceDeployments(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+			"annotation":       "initial",
+		},
+	})
+
+	source, err := c.getSourceById(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.Attributes.Deployment.Annotation = "changed default params"
+	source.Attributes.Deployment.DefaultParams = map[string]interface{}{"auto": "format"}
+	if _, err = c.updateSource(context.Background(), source); err != nil {
+		t.Fatal(err)
+	}
+
+	deployments, err := c.listSourceDeployments(context.Background(), id)
+	if err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	if len(deployments) != 2 {
+		t.Fatalf("expected 2 deployments, got %d", len(deployments))
+	}
+
+	latest := deployments[0].Attributes
+	if *latest.Annotation != "changed default params" || latest.Deployment.DefaultParams["auto"] != "format" {
+		t.Errorf("latest deployment doesnt match expected: %+v", latest)
+	}
+
+	if *deployments[1].Attributes.Annotation != "initial" || *deployments[1].Attributes.DeploymentStatus != "deployed" {
+		t.Errorf("first deployment doesnt match expected: %+v", deployments[1].Attributes)
+	}
+
+	if _, err = c.listSourceDeployments(context.Background(), "missing"); err == nil {
+		t.Error("listing deployments of a missing source should fail")
+	}
+}
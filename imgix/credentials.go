@@ -0,0 +1,144 @@
+package imgix
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCredentialsFile = "~/.imgix/credentials"
+	defaultProfile         = "default"
+
+	// profileEnv names the profile used when neither profile nor api_key is set in the provider configuration
+	profileEnv = "IMGIX_PROFILE"
+
+	credentialsCommandTimeout = time.Minute
+)
+
+// apiKeyFromResourceData resolves the API key of the provider. An explicit credentials_command wins, then
+// profile or api_key, then the IMGIX_PROFILE profile, and the default profile is used when nothing else is configured.
+func apiKeyFromResourceData(ctx context.Context, d *schema.ResourceData) (string, error) {
+	if command := SliceString(d.Get("credentials_command").([]interface{})); len(command) > 0 {
+		return runCredentialsCommand(ctx, command)
+	}
+
+	path := d.Get("credentials_file").(string)
+	if profile := d.Get("profile").(string); profile != "" {
+		return loadProfileApiKey(path, profile)
+	}
+
+	if key := d.Get("api_key").(string); key != "" {
+		return key, nil
+	}
+
+	if profile := os.Getenv(profileEnv); profile != "" {
+		return loadProfileApiKey(path, profile)
+	}
+
+	key, err := loadProfileApiKey(path, defaultProfile)
+	if os.IsNotExist(err) {
+		// missing keys are reported by NewClient
+		return "", nil
+	}
+	return key, err
+}
+
+// loadProfileApiKey reads the api_key of a profile from an ini style credentials file
+func loadProfileApiKey(path, profile string) (string, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return "", err
+	}
+
+	profiles, err := readCredentialsFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	values, ok := profiles[profile]
+	if !ok {
+		return "", fmt.Errorf("Profile %s not found in %s", profile, path)
+	}
+
+	key := values["api_key"]
+	if key == "" {
+		return "", fmt.Errorf("Profile %s in %s has no api_key", profile, path)
+	}
+	return key, nil
+}
+
+func readCredentialsFile(path string) (map[string]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	profiles := map[string]map[string]string{}
+	var current map[string]string
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		switch {
+		case text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";"):
+			continue
+		case strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]"):
+			name := strings.TrimSpace(text[1 : len(text)-1])
+			current = map[string]string{}
+			profiles[name] = current
+		case strings.Contains(text, "=") && current != nil:
+			parts := strings.SplitN(text, "=", 2)
+			current[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		default:
+			return nil, fmt.Errorf("Invalid credentials file %s on line %d", path, line)
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// runCredentialsCommand runs an external helper and uses its trimmed standard output as the API key
+func runCredentialsCommand(ctx context.Context, command []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, credentialsCommandTimeout)
+	defer cancel()
+
+	log.Printf("[DEBUG] Running imgix credentials command %s", command[0])
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("Error running credentials command %s: %s: %s", command[0], err.Error(), strings.TrimSpace(stderr.String()))
+	}
+
+	key := strings.TrimSpace(stdout.String())
+	if key == "" {
+		return "", fmt.Errorf("Credentials command %s returned an empty API key", command[0])
+	}
+	return key, nil
+}
+
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("Error expanding %s: %s", path, err.Error())
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
@@ -0,0 +1,159 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"io/ioutil"
+	"path/filepath"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+const testCredentialsFile = `
+# imgix accounts
+[default]
+api_key = default-key
+
+[staging]
+api_key=staging-key
+
+[empty]
+`
+
+func writeTestCredentialsFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "credentials")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadingProfileApiKey(t *testing.T) {
+	path := writeTestCredentialsFile(t, testCredentialsFile)
+
+	cases := map[string]string{
+		"default": "default-key",
+		"staging": "staging-key",
+		"empty":   "",
+		"missing": "",
+	}
+
+	for profile, expected := range cases {
+		t.Run(profile, func(t *testing.T) {
+			key, err := loadProfileApiKey(path, profile)
+			if expected == "" {
+				if err == nil {
+					t.Errorf("profile %s should fail, got key %s", profile, key)
+				}
+				return
+			}
+
+			if err != nil || key != expected {
+				t.Errorf("profile %s should have key %s, got %s, %v", profile, expected, key, err)
+			}
+		})
+	}
+}
+
+func TestLoadingInvalidCredentialsFile(t *testing.T) {
+	path := writeTestCredentialsFile(t, "api_key = outside-profile\n")
+	if _, err := loadProfileApiKey(path, "default"); err == nil {
+		t.Error("keys outside of a profile should be invalid")
+	}
+}
+
+func TestRunningCredentialsCommand(t *testing.T) {
+	key, err := runCredentialsCommand(context.Background(), []string{"echo", "command-key"})
+	if err != nil || key != "command-key" {
+		t.Errorf("expected command-key, got %s, %v", key, err)
+	}
+
+	if _, err = runCredentialsCommand(context.Background(), []string{"sh", "-c", "echo denied >&2; exit 1"}); err == nil {
+		t.Error("failing commands should return an error")
+	}
+
+	if _, err = runCredentialsCommand(context.Background(), []string{"true"}); err == nil {
+		t.Error("empty output should return an error")
+	}
+}
+
+func TestProfileConflictsWithApiKey(t *testing.T) {
+	config := terraform.NewResourceConfigRaw(map[string]interface{}{
+		"api_key": "key",
+		"profile": "production",
+	})
+	if diags := Provider().Validate(config); !diags.HasError() {
+		t.Error("profile and api_key should conflict")
+	}
+}
+
+func TestCredentialsCommandConflictsWithExplicitKeys(t *testing.T) {
+	for _, key := range []string{"api_key", "profile"} {
+		config := terraform.NewResourceConfigRaw(map[string]interface{}{
+			"credentials_command": []interface{}{"echo", "key"},
+			key:                   "production",
+		})
+		if diags := Provider().Validate(config); !diags.HasError() {
+			t.Errorf("credentials_command and %s should conflict", key)
+		}
+	}
+}
+
+func TestAccImgixProvider_credentials(t *testing.T) {
+	api := startFakeApi(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+		},
+	})
+	path := writeTestCredentialsFile(t, fmt.Sprintf("[production]\napi_key = %s\n", fakeimgix.DefaultApiKey))
+	// api_key set in the configuration wins over the profile of the environment
+	t.Setenv(profileEnv, "missing")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "imgix" {
+  profile          = "production"
+  credentials_file = %q
+}
+
+provider "imgix" {
+  alias   = "key"
+  api_key = %[2]q
+}
+
+provider "imgix" {
+  alias               = "command"
+  credentials_command = ["echo", %[2]q]
+}
+
+data "imgix_source" "profile" {
+  id = %[3]q
+}
+
+data "imgix_source" "command" {
+  provider = imgix.command
+  id       = %[3]q
+}
+
+data "imgix_source" "key" {
+  provider = imgix.key
+  id       = %[3]q
+}
+`, path, fakeimgix.DefaultApiKey, id),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.imgix_source.profile", "name", "source1"),
+					resource.TestCheckResourceAttr("data.imgix_source.command", "name", "source1"),
+					resource.TestCheckResourceAttr("data.imgix_source.key", "name", "source1"),
+				),
+			},
+		},
+	})
+}
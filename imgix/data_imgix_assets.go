@@ -0,0 +1,347 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func dataSourceImgixAssets() *schema.Resource {
+	return &schema.Resource{
+		Description: "Allows listing assets of an Imgix source",
+		ReadContext: dataSourceAssetsRead,
+		Schema: map[string]*schema.Schema{
+			"source_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: assetDescriptions["source_id"],
+			},
+			"path_prefix": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  assetsDescriptions["path_prefix"],
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^/"), "must start with a slash"),
+			},
+			"content_type": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: assetsDescriptions["content_type"],
+			},
+			"tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: assetsDescriptions["tags"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"categories": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: assetsDescriptions["categories"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"created_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  assetsDescriptions["created_after"],
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"created_before": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  assetsDescriptions["created_before"],
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"modified_after": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  assetsDescriptions["modified_after"],
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"modified_before": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  assetsDescriptions["modified_before"],
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"page_size": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      100,
+				Description:  assetsDescriptions["page_size"],
+				ValidateFunc: validation.IntBetween(1, 1000),
+			},
+			"assets": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: assetsDescriptions["assets"],
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: assetDescriptions["id"],
+						},
+						"origin_path": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: assetDescriptions["origin_path"],
+						},
+						"content_type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: assetDescriptions["content_type"],
+						},
+						"file_size": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: assetDescriptions["file_size"],
+						},
+						"media_width": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: assetDescriptions["media_width"],
+						},
+						"media_height": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: assetDescriptions["media_height"],
+						},
+						"etag": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: assetDescriptions["etag"],
+						},
+						"description": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: assetDescriptions["description"],
+						},
+						"tags": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: assetDescriptions["tags"],
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"categories": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: assetDescriptions["categories"],
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"custom_fields": {
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Description: assetDescriptions["custom_fields"],
+							Elem:        &schema.Schema{Type: schema.TypeString},
+						},
+						"date_created": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: assetDescriptions["date_created"],
+						},
+						"date_modified": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: assetDescriptions["date_modified"],
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+type assetFilter struct {
+	pathPrefix     string
+	contentType    string
+	tags           []string
+	categories     []string
+	createdAfter   *time.Time
+	createdBefore  *time.Time
+	modifiedAfter  *time.Time
+	modifiedBefore *time.Time
+}
+
+func dataSourceAssetsRead(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	filter := getAssetFilterFromResourceData(d)
+
+	assets, err := c.listAssets(ctx, sourceId, filter.apiFilters(), d.Get("page_size").(int))
+	if err != nil {
+		return diag.Errorf("Error listing assets of source %s: %s", sourceId, err.Error())
+	}
+
+	var flattened []interface{}
+	for _, a := range assets {
+		// the API may apply filters loosely, so they are checked again
+		if filter.matches(a) {
+			flattened = append(flattened, flattenAsset(a))
+		}
+	}
+
+	d.SetId(sourceId + "/" + strconv.Itoa(schema.HashString(filter.String())))
+	if err = d.Set("assets", flattened); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+func getAssetFilterFromResourceData(d *schema.ResourceData) assetFilter {
+	filter := assetFilter{
+		pathPrefix:  d.Get("path_prefix").(string),
+		contentType: d.Get("content_type").(string),
+		tags:        SliceString(d.Get("tags").(*schema.Set).List()),
+		categories:  SliceString(d.Get("categories").(*schema.Set).List()),
+	}
+	sort.Strings(filter.tags)
+	sort.Strings(filter.categories)
+
+	// values are validated as RFC3339 by the schema
+	parse := func(key string) *time.Time {
+		if v := d.Get(key).(string); v != "" {
+			t, _ := time.Parse(time.RFC3339, v)
+			return &t
+		}
+		return nil
+	}
+	filter.createdAfter = parse("created_after")
+	filter.createdBefore = parse("created_before")
+	filter.modifiedAfter = parse("modified_after")
+	filter.modifiedBefore = parse("modified_before")
+
+	return filter
+}
+
+func (f assetFilter) apiFilters() map[string]string {
+	filters := map[string]string{}
+	if f.pathPrefix != "" {
+		filters["origin_path"] = f.pathPrefix
+	}
+	if f.contentType != "" {
+		filters["content_type"] = f.contentType
+	}
+	if len(f.tags) > 0 {
+		filters["tags"] = strings.Join(f.tags, ",")
+	}
+	if len(f.categories) > 0 {
+		filters["categories"] = strings.Join(f.categories, ",")
+	}
+
+	// date ranges are sent as unix timestamps, gte for the inclusive lower and lt for the exclusive upper bound
+	timestamp := func(t *time.Time) string {
+		return strconv.FormatInt(t.Unix(), 10)
+	}
+	if f.createdAfter != nil {
+		filters["date_created[gte]"] = timestamp(f.createdAfter)
+	}
+	if f.createdBefore != nil {
+		filters["date_created[lt]"] = timestamp(f.createdBefore)
+	}
+	if f.modifiedAfter != nil {
+		filters["date_modified[gte]"] = timestamp(f.modifiedAfter)
+	}
+	if f.modifiedBefore != nil {
+		filters["date_modified[lt]"] = timestamp(f.modifiedBefore)
+	}
+	return filters
+}
+
+func (f assetFilter) matches(a *Asset) bool {
+	if !strings.HasPrefix(a.Attributes.OriginPath, f.pathPrefix) {
+		return false
+	}
+
+	if f.contentType != "" && (a.Attributes.ContentType == nil || !strings.HasPrefix(*a.Attributes.ContentType, f.contentType)) {
+		return false
+	}
+
+	if !containsAllStrings(a.Attributes.Tags, f.tags) || !containsAllStrings(a.Attributes.Categories, f.categories) {
+		return false
+	}
+
+	return timestampInRange(a.Attributes.DateCreated, f.createdAfter, f.createdBefore) &&
+		timestampInRange(a.Attributes.DateModified, f.modifiedAfter, f.modifiedBefore)
+}
+
+func (f assetFilter) String() string {
+	format := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf(
+		"%s|%s|%s|%s|%s|%s|%s|%s",
+		f.pathPrefix,
+		f.contentType,
+		strings.Join(f.tags, ","),
+		strings.Join(f.categories, ","),
+		format(f.createdAfter),
+		format(f.createdBefore),
+		format(f.modifiedAfter),
+		format(f.modifiedBefore),
+	)
+}
+
+// timestampInRange checks that the unix timestamp is at or after the start and before the end of the range
+func timestampInRange(timestamp *int, after, before *time.Time) bool {
+	if after == nil && before == nil {
+		return true
+	}
+
+	if timestamp == nil {
+		return false
+	}
+
+	t := time.Unix(int64(*timestamp), 0)
+	return (after == nil || !t.Before(*after)) && (before == nil || t.Before(*before))
+}
+
+func containsAllStrings(list, values []string) bool {
+	for _, v := range values {
+		found := false
+		for _, s := range list {
+			if s == v {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func flattenAsset(a *Asset) map[string]interface{} {
+	customFields := make(map[string]interface{}, len(a.Attributes.CustomFields))
+	for k, v := range a.Attributes.CustomFields {
+		customFields[k] = fmt.Sprint(v)
+	}
+
+	return map[string]interface{}{
+		"id":            a.Id,
+		"origin_path":   a.Attributes.OriginPath,
+		"content_type":  a.Attributes.ContentType,
+		"file_size":     a.Attributes.FileSize,
+		"media_width":   a.Attributes.MediaWidth,
+		"media_height":  a.Attributes.MediaHeight,
+		"etag":          a.Attributes.Etag,
+		"description":   a.Attributes.Description,
+		"tags":          a.Attributes.Tags,
+		"categories":    a.Attributes.Categories,
+		"custom_fields": customFields,
+		"date_created":  a.Attributes.DateCreated,
+		"date_modified": a.Attributes.DateModified,
+	}
+}
@@ -0,0 +1,136 @@
+package imgix
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+	"time"
+)
+
+func TestMatchingAssetFilter(t *testing.T) {
+	created := int(time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC).Unix())
+	asset := &Asset{}
+	asset.Attributes.OriginPath = "/images/hero.jpg"
+	asset.Attributes.ContentType = String("image/jpeg")
+	asset.Attributes.Tags = []string{"hero", "summer"}
+	asset.Attributes.Categories = []string{"homepage"}
+	asset.Attributes.DateCreated = &created
+	asset.Attributes.DateModified = &created
+
+	at := func(year int) *time.Time {
+		t := time.Date(year, 1, 1, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	cases := map[string]struct {
+		filter   assetFilter
+		expected bool
+	}{
+		"empty":               {assetFilter{}, true},
+		"path prefix":         {assetFilter{pathPrefix: "/images/"}, true},
+		"other path prefix":   {assetFilter{pathPrefix: "/videos/"}, false},
+		"content type prefix": {assetFilter{contentType: "image/"}, true},
+		"other content type":  {assetFilter{contentType: "image/png"}, false},
+		"subset of tags":      {assetFilter{tags: []string{"hero"}}, true},
+		"missing tag":         {assetFilter{tags: []string{"hero", "winter"}}, false},
+		"category":            {assetFilter{categories: []string{"homepage"}}, true},
+		"missing category":    {assetFilter{categories: []string{"blog"}}, false},
+		"created in range":    {assetFilter{createdAfter: at(2021), createdBefore: at(2022)}, true},
+		"created before":      {assetFilter{createdBefore: at(2021)}, false},
+		"modified after":      {assetFilter{modifiedAfter: at(2022)}, false},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			if matches := c.filter.matches(asset); matches != c.expected {
+				t.Errorf("filter %s should match %v, got %v", c.filter, c.expected, matches)
+			}
+		})
+	}
+}
+
+func TestAccImgixAssetsDataSource_filters(t *testing.T) {
+	api := startFakeApi(t)
+	sourceId := api.SeedSource(map[string]interface{}{
+		"name": "assets",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"assets"},
+		},
+	})
+	api.SeedAsset(sourceId, "/images/hero.jpg", map[string]interface{}{
+		"tags":          []interface{}{"hero"},
+		"custom_fields": map[string]interface{}{"owner": "cms"},
+		"media_width":   1920,
+	})
+	api.SeedAsset(sourceId, "/images/thumb.jpg", map[string]interface{}{})
+	api.SeedAsset(sourceId, "/images/logo.png", map[string]interface{}{
+		"content_type": "image/png",
+		"tags":         []interface{}{"hero"},
+	})
+	api.SeedAsset(sourceId, "/videos/hero.mp4", map[string]interface{}{
+		"content_type": "video/mp4",
+		"tags":         []interface{}{"hero"},
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixAssetsDataSourceConfig(sourceId, `
+  path_prefix  = "/images/"
+  content_type = "image/jpeg"
+  tags         = ["hero"]
+  page_size    = 1
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.imgix_assets.test", "assets.#", "1"),
+					resource.TestCheckResourceAttr("data.imgix_assets.test", "assets.0.origin_path", "/images/hero.jpg"),
+					resource.TestCheckResourceAttr("data.imgix_assets.test", "assets.0.media_width", "1920"),
+					resource.TestCheckResourceAttr("data.imgix_assets.test", "assets.0.custom_fields.owner", "cms"),
+					resource.TestCheckResourceAttrSet("data.imgix_assets.test", "assets.0.etag"),
+				),
+			},
+			{
+				Config: testAccImgixAssetsDataSourceConfig(sourceId, `
+  created_before = "2000-01-01T00:00:00Z"
+`),
+				Check: resource.TestCheckResourceAttr("data.imgix_assets.test", "assets.#", "0"),
+			},
+		},
+	})
+}
+
+func TestValidatingAssetsPathPrefix(t *testing.T) {
+	validate := dataSourceImgixAssets().Schema["path_prefix"].ValidateFunc
+	cases := map[string]bool{
+		"/":        true,
+		"/images/": true,
+		"images/":  false,
+	}
+
+	for c, valid := range cases {
+		t.Run(c, func(t *testing.T) {
+			_, errs := validate(c, "path_prefix")
+			if len(errs) == 0 && !valid {
+				t.Errorf("Prefix %s is invalid", c)
+			} else if len(errs) > 0 && valid {
+				t.Errorf("Prefix %s is valid", c)
+			}
+		})
+	}
+}
+
+func testAccImgixAssetsDataSourceConfig(sourceId, filters string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+data "imgix_assets" "test" {
+  source_id = %[2]q
+%[3]s
+}
+`, fakeimgix.DefaultApiKey, sourceId, filters)
+}
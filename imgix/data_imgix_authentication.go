@@ -0,0 +1,53 @@
+package imgix
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceImgixAuthentication() *schema.Resource {
+	return &schema.Resource{
+		Description: "Allows getting details of the API key used by the provider",
+		ReadContext: func(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+			c := i.(*client)
+			auth := c.authentication
+			if auth == nil {
+				var err error
+				if auth, err = c.getAuthentication(ctx); err != nil {
+					return diag.Errorf("Error reading imgix API key details: %s", err.Error())
+				}
+			}
+
+			d.SetId(auth.Mode + "/" + auth.Tag)
+			d.Set("authorized", auth.Authorized)
+			d.Set("mode", auth.Mode)
+			d.Set("mode_title", auth.ModeTitle)
+			d.Set("tag", auth.Tag)
+
+			return nil
+		},
+		Schema: map[string]*schema.Schema{
+			"authorized": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: authenticationDescriptions["authorized"],
+			},
+			"mode": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: authenticationDescriptions["mode"],
+			},
+			"mode_title": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: authenticationDescriptions["mode_title"],
+			},
+			"tag": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: authenticationDescriptions["tag"],
+			},
+		},
+	}
+}
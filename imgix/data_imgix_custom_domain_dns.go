@@ -0,0 +1,78 @@
+package imgix
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceImgixCustomDomainDns() *schema.Resource {
+	return &schema.Resource{
+		Description: "Checks whether a custom domain of an Imgix source points at imgix",
+		ReadContext: dataSourceCustomDomainDnsRead,
+		Schema: map[string]*schema.Schema{
+			"source_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: customDomainDnsDescriptions["source_id"],
+			},
+			"domain": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      customDomainDnsDescriptions["domain"],
+				ValidateDiagFunc: validateCustomDomain,
+			},
+			"cname_target": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: customDomainDnsDescriptions["cname_target"],
+			},
+			"resolved_cname": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: customDomainDnsDescriptions["resolved_cname"],
+			},
+			"ready": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: customDomainDnsDescriptions["ready"],
+			},
+		},
+	}
+}
+
+func dataSourceCustomDomainDnsRead(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	domain := d.Get("domain").(string)
+
+	source, err := c.getSourceById(ctx, sourceId)
+	if err != nil {
+		return diag.Errorf("Error reading source %s: %s", sourceId, err.Error())
+	}
+
+	if source == nil || source.Id == nil {
+		return diag.Errorf("Source %s not found", sourceId)
+	}
+
+	targets := customDomainCnameTargets(source.Attributes.Deployment.ImgixSubdomains)
+	if len(targets) == 0 {
+		return diag.Errorf("Source %s has no imgix subdomain to point %s at", sourceId, domain)
+	}
+
+	cname, err := c.lookupCname(ctx, domain)
+	if err != nil {
+		return diag.Errorf("Error resolving %s: %s", domain, err.Error())
+	}
+
+	ready, err := c.cnameMatchesTargets(ctx, cname, targets)
+	if err != nil {
+		return diag.Errorf("Error resolving %s: %s", targets[0], err.Error())
+	}
+
+	d.SetId(sourceCustomDomainId(sourceId, domain))
+	d.Set("cname_target", targets[0])
+	d.Set("resolved_cname", cname)
+	d.Set("ready", ready)
+	return nil
+}
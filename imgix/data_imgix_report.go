@@ -0,0 +1,201 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"sort"
+)
+
+func dataSourceImgixReport() *schema.Resource {
+	return &schema.Resource{
+		Description: "Allows getting imgix usage reports of the account or a single source",
+		ReadContext: dataSourceReportRead,
+		Schema: map[string]*schema.Schema{
+			"source_id": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: reportDescriptions["source_id"],
+			},
+			"start_date": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      reportDescriptions["start_date"],
+				ValidateDiagFunc: validateDate,
+			},
+			"end_date": {
+				Type:             schema.TypeString,
+				Required:         true,
+				Description:      reportDescriptions["end_date"],
+				ValidateDiagFunc: validateDate,
+			},
+			"total_bandwidth": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: reportDescriptions["total_bandwidth"],
+			},
+			"total_requests": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: reportDescriptions["total_requests"],
+			},
+			"max_master_images": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: reportDescriptions["max_master_images"],
+			},
+			"cache_hit_ratio": {
+				Type:        schema.TypeFloat,
+				Computed:    true,
+				Description: reportDescriptions["cache_hit_ratio"],
+			},
+			"rows": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: reportDescriptions["rows"],
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"date": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: reportDescriptions["date"],
+						},
+						"source_id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: reportDescriptions["row_source_id"],
+						},
+						"bandwidth": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: reportDescriptions["bandwidth"],
+						},
+						"requests": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: reportDescriptions["requests"],
+						},
+						"master_images": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: reportDescriptions["master_images"],
+						},
+						"cache_hit_ratio": {
+							Type:        schema.TypeFloat,
+							Computed:    true,
+							Description: reportDescriptions["row_cache_hit_ratio"],
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceReportRead(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	startDate := d.Get("start_date").(string)
+	endDate := d.Get("end_date").(string)
+
+	if endDate < startDate {
+		return diag.Errorf("end_date %s is before start_date %s", endDate, startDate)
+	}
+
+	filters := map[string]string{
+		"report_type":  ReportTypeUsage,
+		"period_start": startDate,
+		"period_end":   endDate,
+	}
+	if sourceId != "" {
+		filters["source_id"] = sourceId
+	}
+
+	reports, err := c.listReports(ctx, filters)
+	if err != nil {
+		return diag.Errorf("Error listing reports: %s", err.Error())
+	}
+
+	var rows []reportRow
+	for _, report := range reports {
+		reportRows, err := c.downloadReport(ctx, report)
+		if err != nil {
+			return diag.Errorf("Error reading report %s: %s", StringValue(report.Id), err.Error())
+		}
+		rows = append(rows, reportRows...)
+	}
+
+	rows = filterReportRows(rows, sourceId, startDate, endDate)
+
+	var totalBandwidth, totalRequests, maxMasterImages int64
+	var cacheHits float64
+	flattened := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		totalBandwidth += row.Bandwidth
+		totalRequests += row.Requests
+		cacheHits += row.CacheHitRatio * float64(row.Requests)
+		if row.MasterImages > maxMasterImages {
+			maxMasterImages = row.MasterImages
+		}
+
+		flattened = append(flattened, map[string]interface{}{
+			"date":            row.Date,
+			"source_id":       row.SourceId,
+			"bandwidth":       row.Bandwidth,
+			"requests":        row.Requests,
+			"master_images":   row.MasterImages,
+			"cache_hit_ratio": row.CacheHitRatio,
+		})
+	}
+
+	cacheHitRatio := 0.0
+	if totalRequests > 0 {
+		cacheHitRatio = cacheHits / float64(totalRequests)
+	}
+
+	id := sourceId
+	if id == "" {
+		id = "account"
+	}
+	d.SetId(fmt.Sprintf("%s/%s/%s", id, startDate, endDate))
+	d.Set("total_bandwidth", totalBandwidth)
+	d.Set("total_requests", totalRequests)
+	d.Set("max_master_images", maxMasterImages)
+	d.Set("cache_hit_ratio", cacheHitRatio)
+	if err = d.Set("rows", flattened); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
+
+// filterReportRows drops rows outside of the date range or of other sources and
+// removes duplicates of overlapping reports, the result is sorted by date and source
+func filterReportRows(rows []reportRow, sourceId, startDate, endDate string) []reportRow {
+	unique := map[string]reportRow{}
+	for _, row := range rows {
+		// dates are in YYYY-MM-DD format, so they can be compared as strings
+		if row.Date < startDate || row.Date > endDate {
+			continue
+		}
+
+		if sourceId != "" && row.SourceId != "" && row.SourceId != sourceId {
+			continue
+		}
+
+		unique[row.Date+"/"+row.SourceId] = row
+	}
+
+	filtered := make([]reportRow, 0, len(unique))
+	for _, row := range unique {
+		filtered = append(filtered, row)
+	}
+
+	sort.Slice(filtered, func(i, j int) bool {
+		if filtered[i].Date != filtered[j].Date {
+			return filtered[i].Date < filtered[j].Date
+		}
+		return filtered[i].SourceId < filtered[j].SourceId
+	})
+	return filtered
+}
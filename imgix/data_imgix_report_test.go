@@ -0,0 +1,76 @@
+package imgix
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"regexp"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestFilteringReportRows(t *testing.T) {
+	rows := []reportRow{
+		{Date: "2021-02-02", SourceId: "abc", Requests: 1},
+		{Date: "2021-01-31", SourceId: "abc", Requests: 2},
+		{Date: "2021-02-01", SourceId: "def", Requests: 3},
+		{Date: "2021-02-01", SourceId: "abc", Requests: 4},
+		{Date: "2021-02-02", SourceId: "abc", Requests: 5},
+	}
+
+	filtered := filterReportRows(rows, "abc", "2021-02-01", "2021-02-28")
+	if len(filtered) != 2 {
+		t.Fatalf("expected 2 rows, got %+v", filtered)
+	}
+
+	if filtered[0].Requests != 4 || filtered[1].Requests != 5 {
+		t.Errorf("rows should be sorted and deduplicated, got %+v", filtered)
+	}
+}
+
+func TestAccImgixReportDataSource_basic(t *testing.T) {
+	api := startFakeApi(t)
+	api.SeedReport("", "2021-01-01", "2021-01-31", "csv", []fakeimgix.UsageRow{
+		{Date: "2021-01-30", SourceId: "abc", Bandwidth: 1000, Requests: 100, MasterImages: 5, CacheHitRatio: 1},
+		{Date: "2021-01-31", SourceId: "abc", Bandwidth: 2000, Requests: 100, MasterImages: 7, CacheHitRatio: 0.5},
+	})
+	api.SeedReport("", "2021-02-01", "2021-02-28", "json", []fakeimgix.UsageRow{
+		{Date: "2021-02-01", SourceId: "abc", Bandwidth: 3000, Requests: 200, MasterImages: 6, CacheHitRatio: 0.5},
+		{Date: "2021-02-02", SourceId: "abc", Bandwidth: 9999, Requests: 999},
+	})
+	api.SeedReport("abc", "2021-02-01", "2021-02-28", "csv", nil)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixReportConfig("2021-01-31", "2021-02-01"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.imgix_report.test", "rows.#", "2"),
+					resource.TestCheckResourceAttr("data.imgix_report.test", "rows.0.date", "2021-01-31"),
+					resource.TestCheckResourceAttr("data.imgix_report.test", "rows.1.date", "2021-02-01"),
+					resource.TestCheckResourceAttr("data.imgix_report.test", "total_bandwidth", "5000"),
+					resource.TestCheckResourceAttr("data.imgix_report.test", "total_requests", "300"),
+					resource.TestCheckResourceAttr("data.imgix_report.test", "max_master_images", "7"),
+					resource.TestCheckResourceAttr("data.imgix_report.test", "cache_hit_ratio", "0.5"),
+				),
+			},
+			{
+				Config:      testAccImgixReportConfig("2021-02-01", "2021-01-31"),
+				ExpectError: regexp.MustCompile("end_date 2021-01-31 is before start_date 2021-02-01"),
+			},
+		},
+	})
+}
+
+func testAccImgixReportConfig(startDate, endDate string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %q
+}
+
+data "imgix_report" "test" {
+  start_date = %q
+  end_date   = %q
+}
+`, fakeimgix.DefaultApiKey, startDate, endDate)
+}
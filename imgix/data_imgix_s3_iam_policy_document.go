@@ -0,0 +1,178 @@
+package imgix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"strconv"
+	"strings"
+)
+
+type iamPolicyDocument struct {
+	Version   string               `json:"Version"`
+	Statement []iamPolicyStatement `json:"Statement"`
+}
+
+type iamPolicyStatement struct {
+	Sid       string                       `json:"Sid"`
+	Effect    string                       `json:"Effect"`
+	Action    []string                     `json:"Action"`
+	Resource  string                       `json:"Resource"`
+	Condition map[string]map[string]string `json:"Condition,omitempty"`
+}
+
+func dataSourceImgixS3IamPolicyDocument() *schema.Resource {
+	return &schema.Resource{
+		Description: "Generates the least-privilege access policies imgix needs for the origin of a source",
+		ReadContext: dataSourceS3IamPolicyDocumentRead,
+		Schema: map[string]*schema.Schema{
+			"bucket": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: s3IamPolicyDocumentDescriptions["bucket"],
+			},
+			"prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: s3IamPolicyDocumentDescriptions["prefix"],
+			},
+			"allows_upload": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: s3IamPolicyDocumentDescriptions["allows_upload"],
+			},
+			"partition": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "aws",
+				Description: s3IamPolicyDocumentDescriptions["partition"],
+			},
+			"json": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: s3IamPolicyDocumentDescriptions["json"],
+			},
+			"gcs_roles": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: s3IamPolicyDocumentDescriptions["gcs_roles"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"gcs_condition": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: s3IamPolicyDocumentDescriptions["gcs_condition"],
+			},
+			"azure_role": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: s3IamPolicyDocumentDescriptions["azure_role"],
+			},
+			"azure_data_actions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: s3IamPolicyDocumentDescriptions["azure_data_actions"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func dataSourceS3IamPolicyDocumentRead(_ context.Context, d *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	bucket := d.Get("bucket").(string)
+	prefix := normalizeOriginPrefix(d.Get("prefix").(string))
+	allowsUpload := d.Get("allows_upload").(bool)
+
+	policy, err := s3IamPolicyJson(d.Get("partition").(string), bucket, prefix, allowsUpload)
+	if err != nil {
+		return diag.Errorf("Error encoding policy of bucket %s: %s", bucket, err.Error())
+	}
+
+	gcsRoles := []string{"roles/storage.objectViewer"}
+	azureRole := "Storage Blob Data Reader"
+	azureDataActions := []string{"Microsoft.Storage/storageAccounts/blobServices/containers/blobs/read"}
+	if allowsUpload {
+		gcsRoles = append(gcsRoles, "roles/storage.objectCreator")
+		azureRole = "Storage Blob Data Contributor"
+		azureDataActions = append(
+			azureDataActions,
+			"Microsoft.Storage/storageAccounts/blobServices/containers/blobs/write",
+			"Microsoft.Storage/storageAccounts/blobServices/containers/blobs/add/action",
+		)
+	}
+
+	d.SetId(strconv.Itoa(schema.HashString(policy)))
+	d.Set("json", policy)
+	d.Set("gcs_roles", gcsRoles)
+	d.Set("gcs_condition", gcsPrefixCondition(bucket, prefix))
+	d.Set("azure_role", azureRole)
+	d.Set("azure_data_actions", azureDataActions)
+	return nil
+}
+
+// s3IamPolicyJson allows listing and reading objects under the prefix, and uploading them when allowsUpload is set
+func s3IamPolicyJson(partition, bucket, prefix string, allowsUpload bool) (string, error) {
+	bucketArn := fmt.Sprintf("arn:%s:s3:::%s", partition, bucket)
+	objectsArn := bucketArn + "/*"
+	var listCondition map[string]map[string]string
+	if prefix != "" {
+		objectsArn = fmt.Sprintf("%s/%s/*", bucketArn, prefix)
+		listCondition = map[string]map[string]string{"StringLike": {"s3:prefix": prefix + "/*"}}
+	}
+
+	policy := iamPolicyDocument{
+		Version: "2012-10-17",
+		Statement: []iamPolicyStatement{
+			{
+				Sid:      "ImgixGetBucketLocation",
+				Effect:   "Allow",
+				Action:   []string{"s3:GetBucketLocation"},
+				Resource: bucketArn,
+			},
+			{
+				Sid:       "ImgixListBucket",
+				Effect:    "Allow",
+				Action:    []string{"s3:ListBucket"},
+				Resource:  bucketArn,
+				Condition: listCondition,
+			},
+			{
+				Sid:      "ImgixGetObject",
+				Effect:   "Allow",
+				Action:   []string{"s3:GetObject"},
+				Resource: objectsArn,
+			},
+		},
+	}
+
+	if allowsUpload {
+		policy.Statement = append(policy.Statement, iamPolicyStatement{
+			Sid:      "ImgixPutObject",
+			Effect:   "Allow",
+			Action:   []string{"s3:PutObject"},
+			Resource: objectsArn,
+		})
+	}
+
+	b, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// gcsPrefixCondition is a CEL expression limiting a bucket IAM binding to objects under the prefix, empty without a prefix
+func gcsPrefixCondition(bucket, prefix string) string {
+	if prefix == "" {
+		return ""
+	}
+	return fmt.Sprintf(`resource.name.startsWith("projects/_/buckets/%s/objects/%s/")`, bucket, prefix)
+}
+
+// normalizeOriginPrefix removes slashes around the prefix, imgix joins it with the image path itself
+func normalizeOriginPrefix(prefix string) string {
+	return strings.Trim(prefix, "/")
+}
@@ -0,0 +1,142 @@
+package imgix
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestGeneratingS3IamPolicy(t *testing.T) {
+	policy, err := s3IamPolicyJson("aws", "images", "cms", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "ImgixGetBucketLocation",
+      "Effect": "Allow",
+      "Action": [
+        "s3:GetBucketLocation"
+      ],
+      "Resource": "arn:aws:s3:::images"
+    },
+    {
+      "Sid": "ImgixListBucket",
+      "Effect": "Allow",
+      "Action": [
+        "s3:ListBucket"
+      ],
+      "Resource": "arn:aws:s3:::images",
+      "Condition": {
+        "StringLike": {
+          "s3:prefix": "cms/*"
+        }
+      }
+    },
+    {
+      "Sid": "ImgixGetObject",
+      "Effect": "Allow",
+      "Action": [
+        "s3:GetObject"
+      ],
+      "Resource": "arn:aws:s3:::images/cms/*"
+    },
+    {
+      "Sid": "ImgixPutObject",
+      "Effect": "Allow",
+      "Action": [
+        "s3:PutObject"
+      ],
+      "Resource": "arn:aws:s3:::images/cms/*"
+    }
+  ]
+}`
+	if policy != expected {
+		t.Errorf("policy should be\n%s\ngot\n%s", expected, policy)
+	}
+}
+
+func TestGeneratingS3IamPolicyWithoutPrefix(t *testing.T) {
+	policy, err := s3IamPolicyJson("aws-cn", "images", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Sid": "ImgixGetBucketLocation",
+      "Effect": "Allow",
+      "Action": [
+        "s3:GetBucketLocation"
+      ],
+      "Resource": "arn:aws-cn:s3:::images"
+    },
+    {
+      "Sid": "ImgixListBucket",
+      "Effect": "Allow",
+      "Action": [
+        "s3:ListBucket"
+      ],
+      "Resource": "arn:aws-cn:s3:::images"
+    },
+    {
+      "Sid": "ImgixGetObject",
+      "Effect": "Allow",
+      "Action": [
+        "s3:GetObject"
+      ],
+      "Resource": "arn:aws-cn:s3:::images/*"
+    }
+  ]
+}`
+	if policy != expected {
+		t.Errorf("policy should be\n%s\ngot\n%s", expected, policy)
+	}
+}
+
+func TestAccImgixS3IamPolicyDocument_basic(t *testing.T) {
+	api := startFakeApi(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixS3IamPolicyDocumentConfig(),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.imgix_s3_iam_policy_document.read", "json"),
+					resource.TestCheckResourceAttr("data.imgix_s3_iam_policy_document.read", "gcs_roles.#", "1"),
+					resource.TestCheckResourceAttr("data.imgix_s3_iam_policy_document.read", "gcs_condition", `resource.name.startsWith("projects/_/buckets/images/objects/cms/")`),
+					resource.TestCheckResourceAttr("data.imgix_s3_iam_policy_document.read", "azure_role", "Storage Blob Data Reader"),
+					resource.TestCheckResourceAttr("data.imgix_s3_iam_policy_document.upload", "gcs_roles.1", "roles/storage.objectCreator"),
+					resource.TestCheckResourceAttr("data.imgix_s3_iam_policy_document.upload", "gcs_condition", ""),
+					resource.TestCheckResourceAttr("data.imgix_s3_iam_policy_document.upload", "azure_role", "Storage Blob Data Contributor"),
+					resource.TestCheckResourceAttr("data.imgix_s3_iam_policy_document.upload", "azure_data_actions.#", "3"),
+				),
+			},
+		},
+	})
+}
+
+func testAccImgixS3IamPolicyDocumentConfig() string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %q
+}
+
+data "imgix_s3_iam_policy_document" "read" {
+  bucket = "images"
+  prefix = "/cms/"
+}
+
+data "imgix_s3_iam_policy_document" "upload" {
+  bucket        = "images"
+  allows_upload = true
+}
+`, fakeimgix.DefaultApiKey)
+}
@@ -12,7 +12,7 @@ func dataSourceImgixSource() *schema.Resource {
 		ReadContext: func(ctx context.Context, data *schema.ResourceData, i interface{}) diag.Diagnostics {
 			c := i.(*client)
 			id := data.Get("id").(string)
-			source, err := c.getSourceById(id)
+			source, err := c.getSourceById(ctx, id)
 			if err != nil {
 				return diag.FromErr(err)
 			}
@@ -47,111 +47,142 @@ func dataSourceImgixSource() *schema.Resource {
 				Computed:    true,
 				Description: sourceDescriptions["date_deployed"],
 			},
+			"enabled": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: sourceDescriptions["enabled"],
+			},
+			"secure_url_token": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: sourceDescriptions["secure_url_token"],
+			},
 			"deployment": {
 				Type:     schema.TypeList,
 				Computed: true,
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						"allows_upload": {
-							Type:        schema.TypeBool,
-							Computed:    true,
-							Description: sourceDescriptions["allows_upload"],
-						},
-						"annotation": {
-							Type:        schema.TypeString,
-							Computed:    true,
-							Description: sourceDescriptions["annotation"],
-						},
-						"cache_ttl_behavior": {
-							Type:        schema.TypeString,
-							Computed:    true,
-							Description: sourceDescriptions["cache_ttl_behavior"],
-						},
-						"cache_ttl_error": {
-							Type:        schema.TypeInt,
-							Computed:    true,
-							Description: sourceDescriptions["cache_ttl_error"],
-						},
-						"cache_ttl_value": {
-							Type:        schema.TypeInt,
-							Computed:    true,
-							Description: sourceDescriptions["cache_ttl_value"],
-						},
-						"crossdomain_xml_enabled": {
-							Type:        schema.TypeBool,
-							Computed:    true,
-							Description: sourceDescriptions["crossdomain_xml_enabled"],
-						},
-						"custom_domains": {
-							Type:        schema.TypeList,
-							Computed:    true,
-							Description: sourceDescriptions["custom_domains"],
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-						},
-						"default_params": {
-							Type:        schema.TypeMap,
-							Computed:    true,
-							Description: sourceDescriptions["default_params"],
-							Elem:        &schema.Schema{Type: schema.TypeString},
-						},
-						"image_error": {
-							Type:        schema.TypeString,
-							Computed:    true,
-							Description: sourceDescriptions["image_error"],
-						},
-						"image_error_append_qs": {
-							Type:        schema.TypeBool,
-							Computed:    true,
-							Description: sourceDescriptions["image_error_append_qs"],
-						},
-						"image_missing": {
-							Type:        schema.TypeString,
-							Computed:    true,
-							Description: sourceDescriptions["image_missing"],
-						},
-						"image_missing_append_qs": {
-							Type:        schema.TypeBool,
-							Computed:    true,
-							Description: sourceDescriptions["image_missing_append_qs"],
-						},
-						"imgix_subdomains": {
-							Type:        schema.TypeList,
-							Computed:    true,
-							Description: sourceDescriptions["imgix_subdomains"],
-							Elem: &schema.Schema{
-								Type: schema.TypeString,
-							},
-						},
-						"secure_url_enabled": {
-							Type:        schema.TypeBool,
-							Computed:    true,
-							Description: sourceDescriptions["secure_url_enabled"],
-						},
-						"type": {
-							Type:        schema.TypeString,
-							Computed:    true,
-							Description: sourceDescriptions["deployment_type"],
-						},
-						"s3_access_key": {
-							Type:        schema.TypeString,
-							Computed:    true,
-							Description: sourceDescriptions["s3_access_key"],
-						},
-						"s3_bucket": {
-							Type:        schema.TypeString,
-							Computed:    true,
-							Description: sourceDescriptions["s3_bucket"],
-						},
-						"s3_prefix": {
-							Type:        schema.TypeString,
-							Computed:    true,
-							Description: sourceDescriptions["s3_prefix"],
-						},
-					},
+				Elem:     dataSourceSourceDeploymentSchema(),
+			},
+		},
+	}
+}
+
+// dataSourceSourceDeploymentSchema describes the read-only deployment block shared by source data sources
+func dataSourceSourceDeploymentSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"allows_upload": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: sourceDescriptions["allows_upload"],
+			},
+			"annotation": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["annotation"],
+			},
+			"cache_ttl_behavior": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["cache_ttl_behavior"],
+			},
+			"cache_ttl_error": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: sourceDescriptions["cache_ttl_error"],
+			},
+			"cache_ttl_value": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: sourceDescriptions["cache_ttl_value"],
+			},
+			"crossdomain_xml_enabled": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: sourceDescriptions["crossdomain_xml_enabled"],
+			},
+			"custom_domains": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: sourceDescriptions["custom_domains"],
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
 				},
 			},
+			"default_params": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: sourceDescriptions["default_params"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"image_error": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["image_error"],
+			},
+			"image_error_append_qs": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: sourceDescriptions["image_error_append_qs"],
+			},
+			"image_missing": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["image_missing"],
+			},
+			"image_missing_append_qs": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: sourceDescriptions["image_missing_append_qs"],
+			},
+			"imgix_subdomains": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: sourceDescriptions["imgix_subdomains"],
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"secure_url_enabled": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: sourceDescriptions["secure_url_enabled"],
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["deployment_type"],
+			},
+			"s3_access_key": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["s3_access_key"],
+			},
+			"s3_bucket": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["s3_bucket"],
+			},
+			"s3_prefix": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["s3_prefix"],
+			},
+			"s3_endpoint": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["s3_endpoint"],
+			},
+			"s3_region": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["s3_region"],
+			},
+			"s3_path_style": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: sourceDescriptions["s3_path_style"],
+			},
 		},
 	}
 }
@@ -0,0 +1,89 @@
+package imgix
+
+import (
+	"context"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceImgixSourceDeployments() *schema.Resource {
+	return &schema.Resource{
+		Description: "Allows listing the deployment history of an Imgix source",
+		ReadContext: dataSourceSourceDeploymentsRead,
+		Schema: map[string]*schema.Schema{
+			"source_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: sourceDeploymentsDescriptions["source_id"],
+			},
+			"deployments": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: sourceDeploymentsDescriptions["deployments"],
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"id": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: sourceDeploymentsDescriptions["id"],
+						},
+						"annotation": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: sourceDescriptions["annotation"],
+						},
+						"date_created": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: sourceDeploymentsDescriptions["date_created"],
+						},
+						"date_deployed": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: sourceDeploymentsDescriptions["date_deployed"],
+						},
+						"deployment_status": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: sourceDeploymentsDescriptions["deployment_status"],
+						},
+						"deployment": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: sourceDeploymentsDescriptions["deployment"],
+							Elem:        dataSourceSourceDeploymentSchema(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceSourceDeploymentsRead(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+
+	deployments, err := c.listSourceDeployments(ctx, sourceId)
+	if err != nil {
+		return diag.Errorf("Error listing deployments of source %s: %s", sourceId, err.Error())
+	}
+
+	flattened := make([]interface{}, 0, len(deployments))
+	for _, deployment := range deployments {
+		flattened = append(flattened, map[string]interface{}{
+			"id":                deployment.Id,
+			"annotation":        deployment.Attributes.Annotation,
+			"date_created":      deployment.Attributes.DateCreated,
+			"date_deployed":     deployment.Attributes.DateDeployed,
+			"deployment_status": deployment.Attributes.DeploymentStatus,
+			"deployment":        []interface{}{flattenSourceDeployment(deployment.Attributes.Deployment)},
+		})
+	}
+
+	d.SetId(sourceId)
+	if err = d.Set("deployments", flattened); err != nil {
+		return diag.FromErr(err)
+	}
+	return nil
+}
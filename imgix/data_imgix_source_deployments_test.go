@@ -0,0 +1,56 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestAccImgixSourceDeploymentsDataSource_basic(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+			"annotation":       "initial",
+		},
+	})
+
+	source, err := c.getSourceById(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.Attributes.Deployment.Annotation = "set auto format"
+	source.Attributes.Deployment.DefaultParams = map[string]interface{}{"auto": "format"}
+	if _, err = c.updateSource(context.Background(), source); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "imgix" {
+  api_key = %q
+}
+
+data "imgix_source_deployments" "test" {
+  source_id = %q
+}
+`, fakeimgix.DefaultApiKey, id),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.imgix_source_deployments.test", "deployments.#", "2"),
+					resource.TestCheckResourceAttr("data.imgix_source_deployments.test", "deployments.0.annotation", "set auto format"),
+					resource.TestCheckResourceAttr("data.imgix_source_deployments.test", "deployments.0.deployment.0.default_params.auto", "format"),
+					resource.TestCheckResourceAttr("data.imgix_source_deployments.test", "deployments.1.annotation", "initial"),
+					resource.TestCheckResourceAttr("data.imgix_source_deployments.test", "deployments.1.deployment_status", "deployed"),
+					resource.TestCheckResourceAttr("data.imgix_source_deployments.test", "deployments.1.deployment.0.default_params.%", "0"),
+				),
+			},
+		},
+	})
+}
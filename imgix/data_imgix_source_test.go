@@ -0,0 +1,44 @@
+package imgix
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestAccImgixSourceDataSource_basic(t *testing.T) {
+	api := startFakeApi(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name":    "source1",
+		"enabled": false,
+		"deployment": map[string]interface{}{
+			"type":               "webfolder",
+			"imgix_subdomains":   []string{"example-1"},
+			"secure_url_enabled": true,
+		},
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "imgix" {
+  api_key = %q
+}
+
+data "imgix_source" "test" {
+  id = %q
+}
+`, fakeimgix.DefaultApiKey, id),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.imgix_source.test", "name", "source1"),
+					resource.TestCheckResourceAttr("data.imgix_source.test", "enabled", "false"),
+					resource.TestCheckResourceAttrSet("data.imgix_source.test", "secure_url_token"),
+					resource.TestCheckResourceAttr("data.imgix_source.test", "deployment.0.imgix_subdomains.0", "example-1"),
+				),
+			},
+		},
+	})
+}
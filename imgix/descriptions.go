@@ -8,7 +8,7 @@ var sourceDescriptions = map[string]string{
 	"enabled":                 "Whether or not a Source is enabled and capable of serving traffic.",
 	"date_deployed":           "Unix timestamp of when this Source was deployed.",
 	"secure_url_token":        "Signing token used for securing images. Only present if deployment.secure_url_enabled is true.",
-	"wait_for_deployed":       "Determines if Terraform should wait for deployed status after any change.",
+	"wait_for_deployed":       "Determines if Terraform should wait for deployed status after any change, or for disabled status when the source is disabled.",
 	"allows_upload":           "Whether imgix has the right permissions for this Source to upload to origin.",
 	"annotation":              "Any comment on the specific deployment.",
 	"cache_ttl_behavior":      "Policy to determine how the TTL on imgix images is set.",
@@ -28,4 +28,150 @@ var sourceDescriptions = map[string]string{
 	"s3_secret_key":           "AWS S3 Secret Access Key.",
 	"s3_bucket":               "AWS S3 bucket name.",
 	"s3_prefix":               "The folder prefix prepended to the image path before resolving the image in S3.",
+	"health_check":            "Fetch a probe image through every imgix subdomain and custom domain of the source after it is created or updated and deployed. Requires wait_for_deployed.",
+	"preflight":               "Check with the deployment credentials that the origin bucket is readable before changes are sent to imgix. Only for s3 and s3_compatible deployments.",
+	"preflight_probe_object":  "Path of an object fetched by the preflight check, resolved under deployment.s3_prefix like image paths.",
+	"s3_endpoint":             "URL of the S3-compatible storage API, such as MinIO or Wasabi. Required for `s3_compatible` deployments.",
+	"s3_region":               "Region of the S3-compatible storage, used to sign requests. Only for `s3_compatible` deployments.",
+	"s3_path_style":           "Address the bucket in the URL path rather than in the hostname. Only for `s3_compatible` deployments.",
+	"effective_deployment":    "Deployment values after the source_defaults of the provider are merged, as sent to imgix.",
+	"ignore_custom_domains":   "Leave custom domains of the source to imgix_source_custom_domain resources. deployment.custom_domains can't be set and domains attached elsewhere are kept.",
+	"ignore_default_params":   "Leave default params of the source to imgix_source_default_param resources. deployment.default_params can't be set, params set elsewhere are kept and source_defaults params aren't applied.",
+	"verify_dns":              "Wait for custom domains added to the deployment to resolve to one of the imgix subdomains of the source before deploying them.",
+}
+
+var assetDescriptions = map[string]string{
+	"id":            "Id of the asset in <source_id>/<origin_path> format",
+	"source_id":     "Id of the source the asset belongs to.",
+	"origin_path":   "Path of the asset in the origin, starting with a slash.",
+	"description":   "Description of the asset.",
+	"tags":          "Tags of the asset.",
+	"categories":    "Categories of the asset.",
+	"custom_fields": "Custom metadata fields of the asset.",
+	"content_type":  "Content type of the asset.",
+	"file_size":     "Size of the asset in bytes.",
+	"media_width":   "Width of the asset in pixels.",
+	"media_height":  "Height of the asset in pixels.",
+	"date_created":  "Unix timestamp of when the asset was added to Asset Manager.",
+	"date_modified": "Unix timestamp of when the asset was last modified.",
+	"etag":          "ETag of the asset file in the origin.",
+}
+
+var assetsDescriptions = map[string]string{
+	"path_prefix":     "Only list assets whose origin path starts with this prefix, starting with a slash.",
+	"content_type":    "Only list assets whose content type starts with this value, e.g. `image/` or `image/png`.",
+	"tags":            "Only list assets which have all of these tags.",
+	"categories":      "Only list assets which have all of these categories.",
+	"created_after":   "Only list assets added to Asset Manager at or after this RFC3339 timestamp.",
+	"created_before":  "Only list assets added to Asset Manager before this RFC3339 timestamp.",
+	"modified_after":  "Only list assets modified at or after this RFC3339 timestamp.",
+	"modified_before": "Only list assets modified before this RFC3339 timestamp.",
+	"page_size":       "Number of assets requested per API call, all pages are always read.",
+	"assets":          "Assets matching the filters.",
+}
+
+var sourceObjectDescriptions = map[string]string{
+	"id":             "Id of the object in <source_id>/<origin_path> format",
+	"source_id":      "Id of the source whose origin the file is uploaded to. The source has to allow uploads.",
+	"origin_path":    "Path of the file in the origin, starting with a slash.",
+	"source":         "Path to a local file to upload. Conflicts with content_base64.",
+	"content_base64": "Base64 encoded content to upload. Conflicts with source.",
+	"content_type":   "Content type of the uploaded file, e.g. image/png.",
+	"content_hash":   "SHA-256 hash of the uploaded content. Changes of the content trigger a new upload.",
+	"asset_id":       "Id of the asset imgix indexed the file as, empty until the upload is indexed. Objects whose asset is removed are uploaded again.",
+}
+
+var assetRefreshDescriptions = map[string]string{
+	"id":              "Random id of the refresh, it changes every time the assets are refreshed.",
+	"source_id":       "Id of the source whose assets are refreshed.",
+	"origin_paths":    "Origin paths of the assets to refresh, starting with a slash. Conflicts with path_prefix.",
+	"path_prefix":     "Refresh all assets whose origin path starts with this prefix. Use `/` for the whole source. Conflicts with origin_paths.",
+	"triggers":        "Arbitrary map of values which cause the assets to be refreshed again when changed, e.g. hashes of uploaded files.",
+	"refreshed_paths": "Origin paths of the assets which were refreshed.",
+}
+
+var sourceDeploymentsDescriptions = map[string]string{
+	"source_id":         "Id of the source.",
+	"deployments":       "Deployments of the source, newest first.",
+	"id":                "Id of the deployment.",
+	"date_created":      "Unix timestamp of when the deployment was requested.",
+	"date_deployed":     "Unix timestamp of when the deployment finished. Empty while it is deploying.",
+	"deployment_status": "Status of the deployment, e.g. deploying or deployed.",
+	"deployment":        "Deployment settings of the source at the time of the deployment.",
+}
+
+var sourceRollbackDescriptions = map[string]string{
+	"id":                "Id of the rollback in <source_id>/<deployment_id> format.",
+	"source_id":         "Id of the source to roll back.",
+	"deployment_id":     "Id of the previous deployment whose settings are re-applied, see the imgix_source_deployments data source.",
+	"annotation":        "Annotation of the rollback deployment. Defaults to `Rollback to deployment <deployment_id>`.",
+	"wait_for_deployed": "Determines if Terraform should wait for the source to be deployed after the rollback, or disabled when the source is disabled.",
+	"deployment":        "Deployment settings of the source after the rollback.",
+}
+
+var sourceCustomDomainDescriptions = map[string]string{
+	"id":                "Id of the custom domain in <source_id>/<domain> format.",
+	"source_id":         "Id of the source the domain is attached to.",
+	"domain":            "Custom domain serving images of the source, e.g. images.example.com.",
+	"wait_for_deployed": "Determines if Terraform should wait for the source to be deployed after the domain is attached or removed.",
+}
+
+var sourceDefaultParamDescriptions = map[string]string{
+	"id":                "Id of the default parameter in <source_id>/<key> format.",
+	"source_id":         "Id of the source the parameter is set on.",
+	"key":               "Name of the imgix rendering parameter, e.g. auto.",
+	"value":             "Value of the parameter.",
+	"wait_for_deployed": "Determines if Terraform should wait for the source to be deployed after the parameter is changed.",
+}
+
+var customDomainDnsDescriptions = map[string]string{
+	"source_id":      "Id of the source serving the domain.",
+	"domain":         "Custom domain to check, e.g. images.example.com.",
+	"cname_target":   "Hostname the domain needs a CNAME record pointing at, the first imgix subdomain of the source.",
+	"resolved_cname": "Canonical name the domain currently resolves to, empty when the domain doesn't exist.",
+	"ready":          "Whether the domain resolves to one of the imgix subdomains of the source.",
+}
+
+var s3IamPolicyDocumentDescriptions = map[string]string{
+	"bucket":             "Name of the bucket or container of the origin.",
+	"prefix":             "Folder prefix of the source, the same as deployment.s3_prefix. Access is limited to objects under it.",
+	"allows_upload":      "Whether to grant permissions for uploading objects, needed for the source to allow uploads.",
+	"partition":          "AWS partition of the bucket ARN.",
+	"json":               "S3 IAM policy document in JSON format.",
+	"gcs_roles":          "Google Cloud Storage roles to bind to the service account of the source on the bucket.",
+	"gcs_condition":      "IAM condition expression limiting the Google Cloud Storage bindings to the prefix. Empty without a prefix.",
+	"azure_role":         "Built-in Azure role to assign to the principal of the source on the container.",
+	"azure_data_actions": "Data actions of an equivalent custom Azure role.",
+}
+
+var reportDescriptions = map[string]string{
+	"source_id":           "Id of the source to get usage of. Usage of the whole account is returned when empty.",
+	"start_date":          "First day of the reported period in YYYY-MM-DD format.",
+	"end_date":            "Last day of the reported period in YYYY-MM-DD format, inclusive.",
+	"total_bandwidth":     "Bandwidth used in the period, in bytes.",
+	"total_requests":      "Number of requests served in the period.",
+	"max_master_images":   "Highest daily number of master images in the period.",
+	"cache_hit_ratio":     "Cache hit ratio of the period, weighted by the number of requests.",
+	"rows":                "Daily usage, sorted by date and source.",
+	"date":                "Day of the usage in YYYY-MM-DD format.",
+	"row_source_id":       "Id of the source, empty for account-wide rows.",
+	"bandwidth":           "Bandwidth used in bytes.",
+	"requests":            "Number of requests served.",
+	"master_images":       "Number of distinct master images served.",
+	"row_cache_hit_ratio": "Ratio of requests served from the CDN cache, between 0 and 1.",
+}
+
+var authenticationDescriptions = map[string]string{
+	"authorized": "Whether the API key is authorized.",
+	"mode":       "Authentication mode of the key, e.g. PUBLIC_APIKEY.",
+	"mode_title": "Human readable authentication mode of the key.",
+	"tag":        "Tag identifying the owner of the key.",
+}
+
+var sourceHealthCheckDescriptions = map[string]string{
+	"path":                  "Path of the probe image, it can include rendering parameters.",
+	"expected_status":       "HTTP status the probe has to be served with.",
+	"expected_content_type": "Prefix of the Content-Type the probe has to be served with.",
+	"timeout":               "How long unhealthy hosts are probed again, as a duration such as 30s or 5m.",
+	"warn_only":             "Report unhealthy hosts as a warning instead of failing the apply.",
 }
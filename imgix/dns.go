@@ -0,0 +1,128 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"go.opentelemetry.io/otel/attribute"
+	"log"
+	"net"
+	"strings"
+	"time"
+)
+
+const imgixDomainSuffix = ".imgix.net"
+
+// dnsPollInterval is how often custom domain records are checked while waiting for them to resolve
+var dnsPollInterval = 10 * time.Second
+
+// newDnsResolver returns a resolver sending queries to the DNS server at address, in host:port format.
+// The system resolver is used when address is empty.
+func newDnsResolver(address string) *net.Resolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// customDomainCnameTargets returns the hostnames a custom domain of a source can point at, the first one is suggested
+func customDomainCnameTargets(imgixSubdomains []string) []string {
+	targets := make([]string, len(imgixSubdomains))
+	for i, subdomain := range imgixSubdomains {
+		targets[i] = subdomain + imgixDomainSuffix
+	}
+	return targets
+}
+
+// lookupCname returns the canonical name of the domain without the trailing dot, or an empty string
+// when the domain doesn't exist
+func (c *client) lookupCname(ctx context.Context, domain string) (string, error) {
+	resolver := c.dnsResolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	cname, err := resolver.LookupCNAME(ctx, domain)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.ToLower(strings.TrimSuffix(cname, ".")), nil
+}
+
+// cnameMatchesTargets reports whether the canonical name of a custom domain is one of the targets. LookupCNAME follows
+// the whole CNAME chain and imgix subdomains are CNAMEs themselves, so the canonical names of the targets match too.
+func (c *client) cnameMatchesTargets(ctx context.Context, cname string, targets []string) (bool, error) {
+	if cname == "" {
+		return false, nil
+	}
+
+	for _, target := range targets {
+		if cname == target {
+			return true, nil
+		}
+	}
+
+	for _, target := range targets {
+		targetCname, err := c.lookupCname(ctx, target)
+		if err != nil {
+			return false, err
+		}
+		if targetCname != "" && cname == targetCname {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// waitForCustomDomainDns waits until the domain has a CNAME record pointing at one of the targets
+func waitForCustomDomainDns(ctx context.Context, c *client, domain string, targets []string, timeout time.Duration) error {
+	log.Printf("[DEBUG] Waiting for %s to resolve to %s", domain, strings.Join(targets, " or "))
+	ctx, span := startSpan(
+		ctx,
+		"imgix.wait_for_custom_domain_dns",
+		attribute.String("imgix.custom_domain", domain),
+		attribute.String("imgix.timeout", timeout.String()),
+	)
+
+	stateConf := &resource.StateChangeConf{
+		Pending:      []string{"unresolved"},
+		Target:       []string{"resolved"},
+		Timeout:      timeout,
+		PollInterval: dnsPollInterval,
+		Refresh: func() (interface{}, string, error) {
+			cname, err := c.lookupCname(ctx, domain)
+			if err != nil {
+				// DNS failures are often temporary, keep waiting
+				log.Printf("[WARN] Error resolving %s: %s", domain, err.Error())
+				return "", "unresolved", nil
+			}
+
+			matches, err := c.cnameMatchesTargets(ctx, cname, targets)
+			if err != nil {
+				log.Printf("[WARN] Error resolving the targets of %s: %s", domain, err.Error())
+				return "", "unresolved", nil
+			}
+			if matches {
+				return cname, "resolved", nil
+			}
+			return cname, "unresolved", nil
+		},
+	}
+
+	_, err := stateConf.WaitForStateContext(ctx)
+	if err != nil {
+		err = fmt.Errorf("%s doesn't resolve to %s: %s", domain, strings.Join(targets, " or "), err.Error())
+	}
+	endSpan(span, err)
+	return err
+}
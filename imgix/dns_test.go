@@ -0,0 +1,187 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"regexp"
+	"terraform-provider-imgix/internal/fakedns"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+	"time"
+)
+
+func startFakeDns(t *testing.T) *fakedns.Server {
+	dns, err := fakedns.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(dns.Close)
+
+	interval := dnsPollInterval
+	dnsPollInterval = 100 * time.Millisecond
+	t.Cleanup(func() { dnsPollInterval = interval })
+	return dns
+}
+
+func TestLookingUpCname(t *testing.T) {
+	dns := startFakeDns(t)
+	dns.SetCNAME("images.example.com", "Example-1.imgix.net")
+
+	c := &client{dnsResolver: newDnsResolver(dns.Addr)}
+	cname, err := c.lookupCname(context.Background(), "images.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cname != "example-1.imgix.net" {
+		t.Errorf("cname should be example-1.imgix.net, got %s", cname)
+	}
+
+	if cname, err = c.lookupCname(context.Background(), "missing.example.com"); err != nil || cname != "" {
+		t.Errorf("missing domain should resolve to an empty cname, got %q: %v", cname, err)
+	}
+}
+
+func TestWaitingForCustomDomainDns(t *testing.T) {
+	dns := startFakeDns(t)
+	c := &client{dnsResolver: newDnsResolver(dns.Addr)}
+	targets := customDomainCnameTargets([]string{"example-1", "example-2"})
+
+	time.AfterFunc(300*time.Millisecond, func() {
+		dns.SetCNAME("images.example.com", "example-2.imgix.net")
+	})
+	if err := waitForCustomDomainDns(context.Background(), c, "images.example.com", targets, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	dns.SetCNAME("other.example.com", "elsewhere.example.net")
+	err := waitForCustomDomainDns(context.Background(), c, "other.example.com", targets, time.Second)
+	if err == nil {
+		t.Error("domain pointing elsewhere should time out")
+	}
+}
+
+func TestWaitingForCustomDomainDnsThroughCdn(t *testing.T) {
+	dns := startFakeDns(t)
+	c := &client{dnsResolver: newDnsResolver(dns.Addr)}
+	targets := customDomainCnameTargets([]string{"example-1"})
+
+	// imgix subdomains are CNAMEs to CDN hosts, so the canonical name of the domain is the CDN host
+	dns.SetCNAME("example-1.imgix.net", "example-1.cdn.example.net")
+	dns.SetCNAME("images.example.com", "example-1.imgix.net")
+
+	matches, err := c.cnameMatchesTargets(context.Background(), "example-1.cdn.example.net", targets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !matches {
+		t.Error("canonical name of the imgix subdomain should match")
+	}
+
+	if err := waitForCustomDomainDns(context.Background(), c, "images.example.com", targets, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	dns.SetCNAME("other.example.com", "other-1.imgix.net")
+	dns.SetCNAME("other-1.imgix.net", "other-1.cdn.example.net")
+	err = waitForCustomDomainDns(context.Background(), c, "other.example.com", targets, time.Second)
+	if err == nil {
+		t.Error("domain pointing at another imgix subdomain should time out")
+	}
+}
+
+func TestAccImgixCustomDomainDns_basic(t *testing.T) {
+	api := startFakeApi(t)
+	dns := startFakeDns(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+		},
+	})
+	dns.SetCNAME("ready.example.com", "example-1.imgix.net")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixCustomDomainDnsConfig(dns.Addr, id),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.imgix_custom_domain_dns.ready", "cname_target", "example-1.imgix.net"),
+					resource.TestCheckResourceAttr("data.imgix_custom_domain_dns.ready", "resolved_cname", "example-1.imgix.net"),
+					resource.TestCheckResourceAttr("data.imgix_custom_domain_dns.ready", "ready", "true"),
+					resource.TestCheckResourceAttr("data.imgix_custom_domain_dns.missing", "resolved_cname", ""),
+					resource.TestCheckResourceAttr("data.imgix_custom_domain_dns.missing", "ready", "false"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccImgixSource_verifyDns(t *testing.T) {
+	api := startFakeApi(t)
+	dns := startFakeDns(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImgixSourceVerifyDnsConfig(dns.Addr, "1s"),
+				ExpectError: regexp.MustCompile("images.example.com doesn't resolve to verify-dns-test.imgix.net"),
+			},
+			{
+				PreConfig: func() {
+					dns.SetCNAME("images.example.com", "verify-dns-test.imgix.net")
+				},
+				Config: testAccImgixSourceVerifyDnsConfig(dns.Addr, "1m"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.custom_domains.0", "images.example.com"),
+				),
+			},
+		},
+	})
+}
+
+func testAccImgixCustomDomainDnsConfig(resolver, sourceId string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key      = %[1]q
+  dns_resolver = %[2]q
+}
+
+data "imgix_custom_domain_dns" "ready" {
+  source_id = %[3]q
+  domain    = "ready.example.com"
+}
+
+data "imgix_custom_domain_dns" "missing" {
+  source_id = %[3]q
+  domain    = "missing.example.com"
+}
+`, fakeimgix.DefaultApiKey, resolver, sourceId)
+}
+
+func testAccImgixSourceVerifyDnsConfig(resolver, timeout string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key      = %[1]q
+  dns_resolver = %[2]q
+}
+
+resource "imgix_source" "test" {
+  name       = "verify-dns-test"
+  verify_dns = true
+
+  deployment {
+    type             = "webfolder"
+    imgix_subdomains = ["verify-dns-test"]
+    custom_domains   = ["images.example.com"]
+  }
+
+  timeouts {
+    create = %[3]q
+  }
+}
+`, fakeimgix.DefaultApiKey, resolver, timeout)
+}
@@ -0,0 +1,152 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.opentelemetry.io/otel/attribute"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// healthCheckPollInterval is how often unhealthy hosts are probed again until the health check times out
+var healthCheckPollInterval = 10 * time.Second
+
+// sourceHealthCheck is the health_check block of imgix_source
+type sourceHealthCheck struct {
+	path                string
+	expectedStatus      int
+	expectedContentType string
+	timeout             time.Duration
+	warnOnly            bool
+}
+
+// sourceHealthCheckFromResourceData returns nil when there is no health_check block
+func sourceHealthCheckFromResourceData(d *schema.ResourceData) *sourceHealthCheck {
+	blocks := d.Get("health_check").([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil
+	}
+
+	block := blocks[0].(map[string]interface{})
+	// the duration is validated in the schema
+	timeout, _ := time.ParseDuration(block["timeout"].(string))
+	return &sourceHealthCheck{
+		path:                "/" + strings.TrimPrefix(block["path"].(string), "/"),
+		expectedStatus:      block["expected_status"].(int),
+		expectedContentType: block["expected_content_type"].(string),
+		timeout:             timeout,
+		warnOnly:            block["warn_only"].(bool),
+	}
+}
+
+// urls returns the probe URL at every imgix subdomain and custom domain of the source
+func (h *sourceHealthCheck) urls(imgixSubdomains, customDomains []string) []string {
+	var urls []string
+	for _, host := range customDomainCnameTargets(imgixSubdomains) {
+		urls = append(urls, "https://"+host+h.path)
+	}
+	for _, host := range customDomains {
+		urls = append(urls, "https://"+host+h.path)
+	}
+	return urls
+}
+
+// probe returns why the response of the URL isn't healthy, or an empty string when it is
+func (h *sourceHealthCheck) probe(ctx context.Context, httpClient *http.Client, url string) string {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err.Error()
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err.Error()
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(res.Body, maxProbeBytes))
+
+	if res.StatusCode != h.expectedStatus {
+		return fmt.Sprintf("status %s, expected %d", res.Status, h.expectedStatus)
+	}
+
+	if contentType := res.Header.Get("Content-Type"); !strings.HasPrefix(contentType, h.expectedContentType) {
+		return fmt.Sprintf("content type %q, expected %s", contentType, h.expectedContentType)
+	}
+	return ""
+}
+
+// checkSourceHealth fetches the probe of the health_check block through every host of the deployed source,
+// retrying unhealthy hosts until the timeout. Failures are warnings when warn_only is set.
+func checkSourceHealth(ctx context.Context, d *schema.ResourceData, c *client, source *Source) diag.Diagnostics {
+	h := sourceHealthCheckFromResourceData(d)
+	if h == nil {
+		return nil
+	}
+
+	// hosts come from the deployed source, custom domains managed outside of it aren't in state
+	deployment := source.Attributes.Deployment
+	urls := h.urls(deployment.ImgixSubdomains, deployment.CustomDomains)
+	log.Printf("[DEBUG] Checking health of source %s at %s", d.Id(), strings.Join(urls, ", "))
+	ctx, span := startSpan(
+		ctx,
+		"imgix.check_source_health",
+		attribute.String("imgix.resource_id", d.Id()),
+		attribute.String("imgix.timeout", h.timeout.String()),
+	)
+
+	reasons := map[string]string{}
+	pending := urls
+	deadline := time.Now().Add(h.timeout)
+	for {
+		var unhealthy []string
+		for _, url := range pending {
+			if reason := h.probe(ctx, c.healthCheckHttpClient, url); reason != "" {
+				reasons[url] = reason
+				unhealthy = append(unhealthy, url)
+			}
+		}
+		pending = unhealthy
+
+		if len(pending) == 0 || !time.Now().Before(deadline) || ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(healthCheckPollInterval):
+		}
+	}
+
+	if len(pending) == 0 {
+		endSpan(span, nil)
+		return nil
+	}
+
+	sort.Strings(pending)
+	details := make([]string, len(pending))
+	for i, url := range pending {
+		details[i] = fmt.Sprintf("%s: %s", url, reasons[url])
+	}
+
+	err := fmt.Errorf("%s", strings.Join(details, "\n"))
+	endSpan(span, err)
+
+	severity := diag.Error
+	if h.warnOnly {
+		severity = diag.Warning
+	}
+	return diag.Diagnostics{
+		{
+			Severity: severity,
+			Summary:  fmt.Sprintf("Source %s isn't serving the health check probe after %s", d.Id(), h.timeout),
+			Detail:   err.Error(),
+		},
+	}
+}
@@ -0,0 +1,265 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"sync"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+	"time"
+)
+
+// fakeServing answers image requests of every host with the content type set for the host
+type fakeServing struct {
+	mu           sync.Mutex
+	contentTypes map[string]string
+	requests     map[string]int
+}
+
+func (f *fakeServing) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests[req.Host]++
+	contentType, ok := f.contentTypes[req.Host]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write([]byte("image"))
+}
+
+func (f *fakeServing) set(host, contentType string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.contentTypes[host] = contentType
+}
+
+func (f *fakeServing) requestsOf(host string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.requests[host]
+}
+
+// hostRewriteTransport sends every request to target, keeping the original host in the Host header
+type hostRewriteTransport struct {
+	target *url.URL
+}
+
+func (t hostRewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Host = req.URL.Host
+	r.URL.Scheme = t.target.Scheme
+	r.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+// startFakeServing returns the fake and a client sending requests of any host to it
+func startFakeServing(t *testing.T) (*fakeServing, *http.Client) {
+	serving := &fakeServing{contentTypes: map[string]string{}, requests: map[string]int{}}
+	server := httptest.NewServer(serving)
+	t.Cleanup(server.Close)
+
+	interval := healthCheckPollInterval
+	healthCheckPollInterval = 100 * time.Millisecond
+	t.Cleanup(func() { healthCheckPollInterval = interval })
+
+	target, _ := url.Parse(server.URL)
+	return serving, &http.Client{Transport: hostRewriteTransport{target: target}}
+}
+
+func TestProbingHealthCheck(t *testing.T) {
+	serving, httpClient := startFakeServing(t)
+	serving.set("healthy.imgix.net", "image/jpeg")
+	serving.set("html.imgix.net", "text/html")
+
+	h := &sourceHealthCheck{path: "/probe.jpg", expectedStatus: http.StatusOK, expectedContentType: "image/"}
+	cases := map[string]string{
+		"https://healthy.imgix.net/probe.jpg": "",
+		"https://html.imgix.net/probe.jpg":    `content type "text/html", expected image/`,
+		"https://missing.imgix.net/probe.jpg": "status 404 Not Found, expected 200",
+	}
+
+	for u, expected := range cases {
+		if reason := h.probe(context.Background(), httpClient, u); reason != expected {
+			t.Errorf("%s should be %q, got %q", u, expected, reason)
+		}
+	}
+}
+
+func TestAccImgixSource_healthCheck(t *testing.T) {
+	api := startFakeApi(t)
+	serving, httpClient := startFakeServing(t)
+	serving.set("health-test.imgix.net", "image/jpeg")
+	serving.set("images.example.com", "text/html")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactoriesWithHealthCheckClient(api.URL, httpClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceHealthCheckConfig("v1", true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "health_check.0.expected_status", "200"),
+					testAccCheckHealthCheckRequests(serving, "health-test.imgix.net", "images.example.com"),
+				),
+			},
+			{
+				Config:      testAccImgixSourceHealthCheckConfig("v2", false),
+				ExpectError: regexp.MustCompile(`https://images.example.com/probe.jpg\?w=10: content type "text/html", expected\s+image/`),
+			},
+			{
+				PreConfig: func() {
+					serving.set("images.example.com", "image/jpeg")
+				},
+				Config: testAccImgixSourceHealthCheckConfig("v3", false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.annotation", "v3"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccImgixSource_healthCheckIgnoringCustomDomains(t *testing.T) {
+	api := startFakeApi(t)
+	serving, httpClient := startFakeServing(t)
+	serving.set("health-test.imgix.net", "image/jpeg")
+	serving.set("images.example.com", "text/html")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactoriesWithHealthCheckClient(api.URL, httpClient),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceHealthCheckIgnoringCustomDomainsConfig("v1"),
+			},
+			{
+				// the domain attached by imgix_source_custom_domain is probed when the source changes
+				Config:      testAccImgixSourceHealthCheckIgnoringCustomDomainsConfig("v2"),
+				ExpectError: regexp.MustCompile(`https://images.example.com/probe.jpg: content type "text/html"`),
+			},
+		},
+	})
+}
+
+func TestAccImgixSource_healthCheckWithoutWaiting(t *testing.T) {
+	api := startFakeApi(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: fmt.Sprintf(`
+provider "imgix" {
+  api_key = %q
+}
+
+resource "imgix_source" "test" {
+  name              = "health-test"
+  wait_for_deployed = false
+
+  deployment {
+    type             = "webfolder"
+    imgix_subdomains = ["health-test"]
+  }
+
+  health_check {
+    path = "/probe.jpg"
+  }
+}
+`, fakeimgix.DefaultApiKey),
+				ExpectError: regexp.MustCompile("health_check requires wait_for_deployed"),
+			},
+		},
+	})
+}
+
+// testAccProviderFactoriesWithHealthCheckClient returns provider factories fetching health check probes with httpClient
+func testAccProviderFactoriesWithHealthCheckClient(apiUrl string, httpClient *http.Client) map[string]func() (*schema.Provider, error) {
+	return map[string]func() (*schema.Provider, error){
+		"imgix": func() (*schema.Provider, error) {
+			p := Provider()
+			p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+				c, diags := configureProvider(ctx, d, apiUrl)
+				if c != nil {
+					c.(*client).healthCheckHttpClient = httpClient
+				}
+				return c, diags
+			}
+			return p, nil
+		},
+	}
+}
+
+func testAccCheckHealthCheckRequests(serving *fakeServing, hosts ...string) resource.TestCheckFunc {
+	return func(*terraform.State) error {
+		for _, host := range hosts {
+			if serving.requestsOf(host) == 0 {
+				return fmt.Errorf("health check probe was not fetched through %s", host)
+			}
+		}
+		return nil
+	}
+}
+
+func testAccImgixSourceHealthCheckConfig(annotation string, warnOnly bool) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_source" "test" {
+  name = "health-test"
+
+  deployment {
+    type             = "webfolder"
+    imgix_subdomains = ["health-test"]
+    custom_domains   = ["images.example.com"]
+    annotation       = %[2]q
+  }
+
+  health_check {
+    path      = "probe.jpg?w=10"
+    timeout   = "1s"
+    warn_only = %[3]t
+  }
+}
+`, fakeimgix.DefaultApiKey, annotation, warnOnly)
+}
+
+func testAccImgixSourceHealthCheckIgnoringCustomDomainsConfig(annotation string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_source" "test" {
+  name                  = "health-test"
+  ignore_custom_domains = true
+
+  deployment {
+    type             = "webfolder"
+    imgix_subdomains = ["health-test"]
+    annotation       = %[2]q
+  }
+
+  health_check {
+    path    = "probe.jpg"
+    timeout = "1s"
+  }
+}
+
+resource "imgix_source_custom_domain" "test" {
+  source_id = imgix_source.test.id
+  domain    = "images.example.com"
+}
+`, fakeimgix.DefaultApiKey, annotation)
+}
@@ -0,0 +1,91 @@
+package imgix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/logging"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const requestIdHeader = "X-Request-Id"
+
+// logApiRequest logs a summary of every API request at DEBUG level,
+// and redacted headers and bodies when TF_LOG is TRACE
+func logApiRequest(req *http.Request, reqBody []byte, res *http.Response, err error, latency time.Duration, retries int) {
+	if err != nil {
+		log.Printf(
+			"[WARN] imgix API request failed: method=%s path=%s latency=%s retries=%d error=%q",
+			req.Method,
+			req.URL.RequestURI(),
+			latency.Round(time.Millisecond),
+			retries,
+			err.Error(),
+		)
+		return
+	}
+
+	log.Printf(
+		"[DEBUG] imgix API request: method=%s path=%s status=%d latency=%s retries=%d request_id=%s",
+		req.Method,
+		req.URL.RequestURI(),
+		res.StatusCode,
+		latency.Round(time.Millisecond),
+		retries,
+		res.Header.Get(requestIdHeader),
+	)
+
+	if logging.LogLevel() != "TRACE" {
+		return
+	}
+
+	resBody, readErr := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = ioutil.NopCloser(bytes.NewReader(resBody))
+	if readErr != nil {
+		log.Printf("[WARN] Error reading imgix API response body for logging: %s", readErr.Error())
+	}
+
+	log.Printf(
+		"[TRACE] imgix API request details: method=%s path=%s headers=%s body=%s",
+		req.Method,
+		req.URL.RequestURI(),
+		formatHeaders(redactHeaders(req.Header)),
+		formatLogBody(reqBody),
+	)
+	log.Printf(
+		"[TRACE] imgix API response details: method=%s path=%s status=%d headers=%s body=%s",
+		req.Method,
+		req.URL.RequestURI(),
+		res.StatusCode,
+		formatHeaders(redactHeaders(res.Header)),
+		formatLogBody(resBody),
+	)
+}
+
+func formatHeaders(headers http.Header) string {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s=%q", k, strings.Join(headers[k], ","))
+	}
+	return "{" + strings.Join(pairs, " ") + "}"
+}
+
+// formatLogBody redacts JSON bodies and replaces any other content, e.g. uploaded files, with its size
+func formatLogBody(body []byte) string {
+	if len(body) == 0 || json.Valid(body) {
+		return string(redactJsonBody(body))
+	}
+	return fmt.Sprintf("<%d bytes>", len(body))
+}
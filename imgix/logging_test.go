@@ -0,0 +1,158 @@
+package imgix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+	"time"
+)
+
+func captureLogs(t *testing.T, level string) *bytes.Buffer {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Setenv("TF_LOG", level)
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+	})
+	return &buf
+}
+
+func createTestSource(t *testing.T, c *client) *Source {
+	source := &Source{Type: String(TypeSource)}
+	source.Attributes.Name = "logging"
+	source.Attributes.Deployment = sourceDeployment{
+		CustomDomains:   []string{},
+		DefaultParams:   map[string]interface{}{},
+		ImgixSubdomains: []string{"logging"},
+		S3AccessKey:     String("AKIABCDEFGHI"),
+		S3SecretKey:     String("very-secret"),
+		S3Bucket:        String("abc-bucket"),
+		Type:            "s3",
+	}
+
+	created, err := c.createSource(context.Background(), source)
+	if err != nil {
+		t.Fatalf("creating source should not fail: %s", err)
+	}
+	return created
+}
+
+func TestLoggingRequestsAtDebugLevel(t *testing.T) {
+	logs := captureLogs(t, "DEBUG")
+	c, _ := prepareFakeApiTest(t)
+	createTestSource(t, c)
+
+	out := logs.String()
+	expected := "[DEBUG] imgix API request: method=POST path=/api/v1/sources status=201"
+	if !strings.Contains(out, expected) {
+		t.Errorf("logs should contain %q:\n%s", expected, out)
+	}
+
+	if !strings.Contains(out, "retries=0 request_id=fake-request-1") {
+		t.Errorf("logs should contain retry count and request id:\n%s", out)
+	}
+
+	if strings.Contains(out, "[TRACE]") {
+		t.Errorf("bodies should only be logged at TRACE level:\n%s", out)
+	}
+}
+
+func TestLoggingRedactedBodiesAtTraceLevel(t *testing.T) {
+	logs := captureLogs(t, "TRACE")
+	c, _ := prepareFakeApiTest(t)
+	createTestSource(t, c)
+
+	out := logs.String()
+	for _, expected := range []string{
+		"[TRACE] imgix API request details: method=POST path=/api/v1/sources",
+		`Authorization="REDACTED"`,
+		`"s3_secret_key":"REDACTED"`,
+		"[TRACE] imgix API response details: method=POST path=/api/v1/sources status=201",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("logs should contain %q:\n%s", expected, out)
+		}
+	}
+
+	for _, secret := range []string{"very-secret", fakeimgix.DefaultApiKey} {
+		if strings.Contains(out, secret) {
+			t.Errorf("logs should not contain %s:\n%s", secret, out)
+		}
+	}
+}
+
+func TestRetryingThrottledRequests(t *testing.T) {
+	logs := captureLogs(t, "DEBUG")
+	c, api := prepareFakeApiTest(t)
+	api.InjectFault(fakeimgix.Fault{
+		Method: http.MethodGet,
+		Status: http.StatusTooManyRequests,
+		Times:  2,
+	})
+
+	if _, err := c.listSources(context.Background(), nil); err != nil {
+		t.Fatalf("throttled request should be retried: %s", err)
+	}
+
+	if out := logs.String(); !strings.Contains(out, "status=200 latency=") || !strings.Contains(out, "retries=2") {
+		t.Errorf("logs should contain successful request after 2 retries:\n%s", out)
+	}
+}
+
+func TestNotRetryingFailedSourceCreation(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	api.InjectFault(fakeimgix.Fault{
+		Method: http.MethodPost,
+		Status: http.StatusServiceUnavailable,
+		Times:  1,
+	})
+
+	source := &Source{}
+	if _, err := c.createSource(context.Background(), source); err == nil || !strings.Contains(err.Error(), "status: 503") {
+		t.Errorf("creating source should fail without retry, got %v", err)
+	}
+}
+
+func TestGivingUpAfterMaxRetries(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	api.InjectFault(fakeimgix.Fault{
+		Method: http.MethodGet,
+		Status: http.StatusServiceUnavailable,
+	})
+
+	res, err := c.doRequest(context.Background(), http.MethodGet, "/api/v1/sources", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 after retries, got %d", res.StatusCode)
+	}
+}
+
+func TestCancellingRetryDelay(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	c.retryDelay = time.Hour
+	api.InjectFault(fakeimgix.Fault{
+		Method: http.MethodGet,
+		Status: http.StatusTooManyRequests,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.listSources(ctx, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("retry delay should be cancelled, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("cancelled request took %s", elapsed)
+	}
+}
@@ -0,0 +1,343 @@
+package imgix
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"go.opentelemetry.io/otel/attribute"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	awsS3Endpoint    = "https://s3.amazonaws.com"
+	awsDefaultRegion = "us-east-1"
+
+	// emptyPayloadHash is the SHA-256 of an empty request body
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3SignedHeaders  = "host;x-amz-content-sha256;x-amz-date"
+
+	// maxProbeBytes limits how much of the probe object is read
+	maxProbeBytes = 10 << 20
+)
+
+// s3ErrorHints explain S3 error codes in terms of the deployment settings
+var s3ErrorHints = map[string]string{
+	"InvalidAccessKeyId":           "Check deployment.s3_access_key.",
+	"SignatureDoesNotMatch":        "Check deployment.s3_secret_key.",
+	"NoSuchBucket":                 "Check deployment.s3_bucket.",
+	"NoSuchKey":                    "Check preflight.probe_object, it is resolved under deployment.s3_prefix like image paths.",
+	"PermanentRedirect":            "Check deployment.s3_region and deployment.s3_endpoint.",
+	"AuthorizationHeaderMalformed": "Check deployment.s3_region.",
+}
+
+// s3OperationPermissions are the IAM actions imgix needs for each preflight operation
+var s3OperationPermissions = map[string]string{
+	"HeadBucket":    "s3:ListBucket",
+	"ListObjectsV2": "s3:ListBucket",
+	"GetObject":     "s3:GetObject",
+}
+
+// s3Origin is the bucket of an s3 or s3_compatible deployment, accessed with the deployment credentials
+type s3Origin struct {
+	endpoint   *url.URL
+	region     string
+	bucket     string
+	prefix     string
+	accessKey  string
+	secretKey  string
+	pathStyle  bool
+	httpClient *http.Client
+}
+
+// newS3Origin returns the origin of the deployment. endpointOverride replaces the AWS endpoint of s3 deployments,
+// requests are path-style then.
+func newS3Origin(deployment map[string]interface{}, endpointOverride string, httpClient *http.Client) (*s3Origin, error) {
+	o := &s3Origin{
+		region:     awsDefaultRegion,
+		bucket:     deployment["s3_bucket"].(string),
+		prefix:     normalizeOriginPrefix(deployment["s3_prefix"].(string)),
+		accessKey:  deployment["s3_access_key"].(string),
+		secretKey:  deployment["s3_secret_key"].(string),
+		httpClient: httpClient,
+	}
+
+	endpoint := awsS3Endpoint
+	switch deployment["type"] {
+	case "s3":
+		if endpointOverride != "" {
+			endpoint = endpointOverride
+			o.pathStyle = true
+		}
+	case "s3_compatible":
+		endpoint = deployment["s3_endpoint"].(string)
+		o.pathStyle = deployment["s3_path_style"].(bool)
+		if region := deployment["s3_region"].(string); region != "" {
+			o.region = region
+		}
+	default:
+		return nil, fmt.Errorf("Preflight checks are only supported for s3 and s3_compatible deployments")
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid S3 endpoint %s: %s", endpoint, err.Error())
+	}
+	o.endpoint = u
+	return o, nil
+}
+
+// preflightSourceOrigin checks with the deployment credentials that imgix will be able to read the origin,
+// when the preflight block is set. Only s3 and s3_compatible deployments can be checked.
+func preflightSourceOrigin(ctx context.Context, d *schema.ResourceData, c *client) diag.Diagnostics {
+	preflights := d.Get("preflight").([]interface{})
+	if len(preflights) == 0 {
+		return nil
+	}
+
+	probeObject := ""
+	if preflight, ok := preflights[0].(map[string]interface{}); ok {
+		probeObject = preflight["probe_object"].(string)
+	}
+
+	deployment := d.Get("deployment").([]interface{})[0].(map[string]interface{})
+	origin, err := newS3Origin(deployment, c.preflightS3Endpoint, c.originHttpClient)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	log.Printf("[DEBUG] Preflight check of bucket %s at %s", origin.bucket, origin.endpoint)
+	ctx, span := startSpan(
+		ctx,
+		"imgix.preflight_origin",
+		attribute.String("imgix.s3_bucket", origin.bucket),
+		attribute.String("imgix.s3_endpoint", origin.endpoint.String()),
+	)
+	err = origin.preflight(ctx, probeObject)
+	endSpan(span, err)
+
+	if err != nil {
+		return diag.Diagnostics{
+			{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("Preflight check of bucket %s failed", origin.bucket),
+				Detail:   err.Error(),
+			},
+		}
+	}
+	return nil
+}
+
+// preflight makes the requests imgix makes to the origin: HeadBucket, ListObjectsV2 under the prefix,
+// and GetObject of the probe object when it is set
+func (o *s3Origin) preflight(ctx context.Context, probeObject string) error {
+	if err := o.check(ctx, "HeadBucket", http.MethodHead, "", nil); err != nil {
+		return err
+	}
+
+	list := url.Values{"list-type": {"2"}, "max-keys": {"1"}}
+	if o.prefix != "" {
+		list.Set("prefix", o.prefix+"/")
+	}
+	if err := o.check(ctx, "ListObjectsV2", http.MethodGet, "", list); err != nil {
+		return err
+	}
+
+	if probeObject == "" {
+		return nil
+	}
+
+	key := strings.TrimPrefix(probeObject, "/")
+	if o.prefix != "" {
+		key = o.prefix + "/" + key
+	}
+	return o.check(ctx, "GetObject", http.MethodGet, key, nil)
+}
+
+func (o *s3Origin) check(ctx context.Context, operation, method, key string, query url.Values) error {
+	target := o.bucket
+	if key != "" {
+		target += "/" + key
+	}
+
+	res, err := o.do(ctx, method, key, query)
+	if err != nil {
+		return fmt.Errorf("%s of %s failed: %s", operation, target, err.Error())
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		_, err = io.Copy(ioutil.Discard, io.LimitReader(res.Body, maxProbeBytes))
+		return err
+	}
+
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 64<<10))
+	_ = xml.Unmarshal(body, &s3Err)
+
+	// HEAD responses have no body
+	if s3Err.Code == "" {
+		switch res.StatusCode {
+		case http.StatusMovedPermanently:
+			s3Err.Code = "PermanentRedirect"
+		case http.StatusForbidden:
+			s3Err.Code = "AccessDenied"
+		case http.StatusNotFound:
+			s3Err.Code = "NoSuchBucket"
+		}
+	}
+
+	msg := fmt.Sprintf("%s of %s at %s returned %s", operation, target, o.endpoint, res.Status)
+	if s3Err.Code != "" {
+		msg += ", " + s3Err.Code
+	}
+	if s3Err.Message != "" {
+		msg += ": " + s3Err.Message
+	}
+
+	hint := s3ErrorHints[s3Err.Code]
+	if s3Err.Code == "AccessDenied" {
+		hint = fmt.Sprintf("Check the credentials and that deployment.s3_access_key is allowed %s.", s3OperationPermissions[operation])
+	}
+	if hint != "" {
+		msg += ". " + hint
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// do sends a signed request. A bucket in another region of AWS is retried at its regional endpoint.
+func (o *s3Origin) do(ctx context.Context, method, key string, query url.Values) (*http.Response, error) {
+	res, err := o.send(ctx, method, key, query)
+	if err != nil {
+		return nil, err
+	}
+
+	region := res.Header.Get("x-amz-bucket-region")
+	if region == "" || region == o.region || o.endpoint.String() != awsS3Endpoint {
+		return res, nil
+	}
+	res.Body.Close()
+
+	log.Printf("[DEBUG] Bucket %s is in %s, retrying at the regional endpoint", o.bucket, region)
+	o.region = region
+	o.endpoint = &url.URL{Scheme: "https", Host: "s3." + region + ".amazonaws.com"}
+	return o.send(ctx, method, key, query)
+}
+
+func (o *s3Origin) send(ctx context.Context, method, key string, query url.Values) (*http.Response, error) {
+	u := *o.endpoint
+	p := strings.TrimSuffix(u.Path, "/")
+	if o.pathStyle {
+		p += "/" + o.bucket
+	} else {
+		u.Host = o.bucket + "." + u.Host
+	}
+	if key != "" || !o.pathStyle {
+		p += "/" + key
+	}
+	u.Path = p
+	u.RawPath = awsUriEncode(p, false)
+	u.RawQuery = awsCanonicalQuery(query)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	signS3Request(req, o.accessKey, o.secretKey, o.region, time.Now())
+	return o.httpClient.Do(req)
+}
+
+// signS3Request adds AWS Signature Version 4 headers to a request without body
+func signS3Request(req *http.Request, accessKey, secretKey, region string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", emptyPayloadHash)
+
+	canonicalUri := req.URL.EscapedPath()
+	if canonicalUri == "" {
+		canonicalUri = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalUri,
+		req.URL.RawQuery,
+		"host:" + req.URL.Host,
+		"x-amz-content-sha256:" + emptyPayloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		s3SignedHeaders,
+		emptyPayloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	key := hmacSha256([]byte("AWS4"+secretKey), date)
+	key = hmacSha256(key, region)
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKey, scope, s3SignedHeaders, signature,
+	))
+}
+
+// awsCanonicalQuery encodes the query with sorted keys, as required by the signature
+func awsCanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, awsUriEncode(k, true)+"="+awsUriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsUriEncode percent-encodes everything except unreserved characters, and slashes unless encodeSlash is set
+func awsUriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~' || c == '/' && !encodeSlash {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
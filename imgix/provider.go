@@ -16,23 +16,110 @@ func Provider() *schema.Provider {
 		Schema: map[string]*schema.Schema{
 			"api_key": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Sensitive:   true,
 				Description: "Imgix API key. Can also be sourced from IMGIX_API_KEY environment variable",
 				DefaultFunc: schema.EnvDefaultFunc("IMGIX_API_KEY", nil),
 			},
+			"profile": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Description:   "Name of the credentials file profile to take the API key from. Conflicts with api_key. Can also be sourced from IMGIX_PROFILE environment variable, which is only used without api_key",
+				ConflictsWith: []string{"api_key"},
+			},
+			"credentials_file": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of the ini style credentials file with profiles. Defaults to ~/.imgix/credentials. Can also be sourced from IMGIX_CREDENTIALS_FILE environment variable",
+				DefaultFunc: schema.EnvDefaultFunc("IMGIX_CREDENTIALS_FILE", defaultCredentialsFile),
+			},
+			"credentials_command": {
+				Type:          schema.TypeList,
+				Optional:      true,
+				Description:   "Command and arguments of a helper printing the API key to standard output, run when the provider is configured. Conflicts with profile and api_key",
+				Elem:          &schema.Schema{Type: schema.TypeString},
+				ConflictsWith: []string{"api_key", "profile"},
+			},
+			"skip_credentials_validation": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip checking the API key with an API request when the provider is configured",
+			},
+			"dns_resolver": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Address of the DNS server, in host:port format, used to check custom domain records. Defaults to the system resolver. Can also be sourced from IMGIX_DNS_RESOLVER environment variable",
+				DefaultFunc: schema.EnvDefaultFunc("IMGIX_DNS_RESOLVER", nil),
+			},
+			"source_defaults": sourceDefaultsSchema(),
 		},
 		ConfigureContextFunc: func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-			config := Config{
-				AccessKey: d.Get("api_key").(string),
-			}
-			client, err := NewClient(config)
-			return client, diag.FromErr(err)
+			return configureProvider(ctx, d, "")
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"imgix_source": resourceImgixSource(),
+			"imgix_source":               resourceImgixSource(),
+			"imgix_asset":                resourceImgixAsset(),
+			"imgix_asset_refresh":        resourceImgixAssetRefresh(),
+			"imgix_source_custom_domain": resourceImgixSourceCustomDomain(),
+			"imgix_source_default_param": resourceImgixSourceDefaultParam(),
+			"imgix_source_object":        resourceImgixSourceObject(),
+			"imgix_source_rollback":      resourceImgixSourceRollback(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"imgix_source": dataSourceImgixSource(),
+			"imgix_assets":                 dataSourceImgixAssets(),
+			"imgix_authentication":         dataSourceImgixAuthentication(),
+			"imgix_custom_domain_dns":      dataSourceImgixCustomDomainDns(),
+			"imgix_report":                 dataSourceImgixReport(),
+			"imgix_s3_iam_policy_document": dataSourceImgixS3IamPolicyDocument(),
+			"imgix_source":                 dataSourceImgixSource(),
+			"imgix_source_deployments":     dataSourceImgixSourceDeployments(),
 		},
 	}
 }
+
+func configFromResourceData(ctx context.Context, d *schema.ResourceData) (Config, error) {
+	key, err := apiKeyFromResourceData(ctx, d)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{AccessKey: key}, nil
+}
+
+// configureProvider creates the client, the default API url is used when apiBaseUrl is empty
+func configureProvider(ctx context.Context, d *schema.ResourceData, apiBaseUrl string) (interface{}, diag.Diagnostics) {
+	config, err := configFromResourceData(ctx, d)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+
+	config.ApiBaseUrl = apiBaseUrl
+	c, err := NewClient(config)
+	if err != nil {
+		return nil, diag.FromErr(err)
+	}
+	c.sourceDefaults = sourceDefaultsFromResourceData(d)
+	c.dnsResolver = newDnsResolver(d.Get("dns_resolver").(string))
+
+	if d.Get("skip_credentials_validation").(bool) {
+		return c, nil
+	}
+	return c, validateCredentials(ctx, c)
+}
+
+// validateCredentials makes sure the API key is authorized. Permission scopes of the key aren't part of
+// meta.authentication, so a key which can't make changes isn't detected here.
+func validateCredentials(ctx context.Context, c *client) diag.Diagnostics {
+	auth, err := c.getAuthentication(ctx)
+	if err != nil {
+		return diag.Errorf("Error validating imgix API key: %s", err.Error())
+	}
+
+	if !auth.Authorized {
+		return diag.Errorf("imgix API key is not authorized")
+	}
+	c.authentication = auth
+
+	return nil
+}
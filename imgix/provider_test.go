@@ -1,7 +1,13 @@
 package imgix
 
 import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"regexp"
+	"terraform-provider-imgix/internal/fakeimgix"
 	"testing"
 )
 
@@ -26,3 +32,70 @@ func TestProvider(t *testing.T) {
 func TestProviderImpl(t *testing.T) {
 	var _ *schema.Provider = Provider()
 }
+
+func TestValidatingCredentials(t *testing.T) {
+	c, _ := prepareFakeApiTest(t)
+	if diags := validateCredentials(context.Background(), c); len(diags) != 0 {
+		t.Errorf("key should be valid, got %v", diags)
+	}
+
+	if c.authentication == nil || c.authentication.Mode != "PUBLIC_APIKEY" {
+		t.Errorf("authentication should be stored on the client, got %+v", c.authentication)
+	}
+
+	invalid, _ := prepareFakeApiTest(t, fakeimgix.WithApiKey("other-key"))
+	if diags := validateCredentials(context.Background(), invalid); !diags.HasError() {
+		t.Error("invalid key should fail validation")
+	}
+}
+
+func TestAccImgixProvider_credentialsValidation(t *testing.T) {
+	api := startFakeApi(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixAuthenticationConfig(fakeimgix.DefaultApiKey, false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("data.imgix_authentication.test", "authorized", "true"),
+					resource.TestCheckResourceAttr("data.imgix_authentication.test", "mode", "PUBLIC_APIKEY"),
+					resource.TestCheckResourceAttr("data.imgix_authentication.test", "tag", "fake@example.com"),
+				),
+			},
+			{
+				Config:      testAccImgixAuthenticationConfig("wrong-key", false),
+				ExpectError: regexp.MustCompile("Error validating imgix API key"),
+			},
+			{
+				// without validation the key only fails when the data source is read
+				Config:      testAccImgixAuthenticationConfig("wrong-key", true),
+				ExpectError: regexp.MustCompile("Error reading imgix API key details"),
+			},
+		},
+	})
+}
+
+func testAccImgixAuthenticationConfig(key string, skipValidation bool) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key                     = %q
+  skip_credentials_validation = %t
+}
+
+data "imgix_authentication" "test" {}
+`, key, skipValidation)
+}
+
+// testAccProviderFactories returns provider factories talking to the API at apiUrl
+func testAccProviderFactories(apiUrl string) map[string]func() (*schema.Provider, error) {
+	return map[string]func() (*schema.Provider, error){
+		"imgix": func() (*schema.Provider, error) {
+			p := Provider()
+			p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
+				return configureProvider(ctx, d, apiUrl)
+			}
+			return p, nil
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package imgix
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const redactedValue = "REDACTED"
+
+var (
+	sensitiveHeaders = []string{
+		"Authorization",
+	}
+
+	sensitiveJsonFields = map[string]bool{
+		"s3_secret_key":    true,
+		"secure_url_token": true,
+		"password":         true,
+	}
+
+	// fields with these suffixes are treated as credentials too, e.g. origin keys of other storage types
+	sensitiveJsonFieldSuffixes = []string{
+		"_secret_key",
+		"_token",
+		"_password",
+		"_private_key",
+		"_api_key",
+	}
+)
+
+func isSensitiveJsonField(name string) bool {
+	if sensitiveJsonFields[name] {
+		return true
+	}
+
+	for _, suffix := range sensitiveJsonFieldSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders returns a copy of headers with credentials replaced
+func redactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for _, h := range sensitiveHeaders {
+		if redacted.Get(h) != "" {
+			redacted.Set(h, redactedValue)
+		}
+	}
+	return redacted
+}
+
+// redactJsonBody replaces credential fields at any depth of a JSON document.
+// Bodies which aren't valid JSON are returned unchanged.
+func redactJsonBody(body []byte) []byte {
+	if len(strings.TrimSpace(string(body))) == 0 {
+		return body
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return body
+	}
+
+	redacted, err := json.Marshal(redactJsonValue(doc))
+	if err != nil {
+		return body
+	}
+	return redacted
+}
+
+func redactJsonValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for k, field := range value {
+			if isSensitiveJsonField(k) && field != nil {
+				value[k] = redactedValue
+			} else {
+				value[k] = redactJsonValue(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = redactJsonValue(item)
+		}
+	}
+	return v
+}
@@ -0,0 +1,41 @@
+package imgix
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestRedactingHeaders(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Authorization", "Bearer "+testApiToken)
+	headers.Set("Content-Type", "application/json")
+
+	redacted := redactHeaders(headers)
+	if redacted.Get("Authorization") != redactedValue {
+		t.Error("authorization header should be redacted")
+	}
+
+	if redacted.Get("Content-Type") != "application/json" {
+		t.Error("content type header should not be redacted")
+	}
+
+	if headers.Get("Authorization") != "Bearer "+testApiToken {
+		t.Error("original headers should not be modified")
+	}
+}
+
+func TestRedactingJsonBody(t *testing.T) {
+	cases := map[string]string{
+		`{"data":{"attributes":{"deployment":{"s3_bucket":"b","s3_secret_key":"secret"}}}}`: `{"data":{"attributes":{"deployment":{"s3_bucket":"b","s3_secret_key":"REDACTED"}}}}`,
+		`{"data":[{"attributes":{"secure_url_token":"token"}}]}`:                            `{"data":[{"attributes":{"secure_url_token":"REDACTED"}}]}`,
+		`{"s3_secret_key":null}`: `{"s3_secret_key":null}`,
+		`not json`:               `not json`,
+		``:                       ``,
+	}
+
+	for body, expected := range cases {
+		if redacted := string(redactJsonBody([]byte(body))); redacted != expected {
+			t.Errorf("body %s was redacted to %s instead of %s", body, redacted, expected)
+		}
+	}
+}
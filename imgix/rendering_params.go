@@ -0,0 +1,68 @@
+package imgix
+
+import "strings"
+
+// renderingParams is the catalog of imgix rendering API parameters, see https://docs.imgix.com/apis/rendering
+var renderingParams = map[string]bool{
+	// adjustment
+	"bri": true, "con": true, "exp": true, "gam": true, "high": true, "hue": true, "invert": true,
+	"sat": true, "shad": true, "sharp": true, "usm": true, "usmrad": true, "vib": true,
+	// automatic
+	"auto": true,
+	// background
+	"bg": true, "bg-remove": true, "bg-replace": true,
+	// blending
+	"blend": true, "blend-align": true, "blend-alpha": true, "blend-color": true, "blend-crop": true,
+	"blend-fit": true, "blend-h": true, "blend-mode": true, "blend-pad": true, "blend-size": true,
+	"blend-w": true, "blend-x": true, "blend-y": true,
+	// border and padding
+	"border": true, "border-bottom": true, "border-left": true, "border-radius": true,
+	"border-radius-inner": true, "border-right": true, "border-top": true,
+	"pad": true, "pad-bottom": true, "pad-left": true, "pad-right": true, "pad-top": true,
+	// color palette
+	"colors": true, "palette": true, "prefix": true,
+	// face detection
+	"faceindex": true, "facepad": true, "faces": true,
+	// fill
+	"fill": true, "fill-color": true,
+	// focal point crop
+	"fp-debug": true, "fp-x": true, "fp-y": true, "fp-z": true,
+	// format
+	"ch": true, "chromasub": true, "colorquant": true, "cs": true, "dl": true, "dpi": true,
+	"fm": true, "lossless": true, "q": true,
+	// mask
+	"corner-radius": true, "mask": true, "mask-bg": true,
+	// noise reduction
+	"nr": true, "nrs": true,
+	// pdf
+	"page": true, "pdf-annotation": true,
+	// pixel density
+	"dpr": true,
+	// rotation
+	"flip": true, "orient": true, "rot": true,
+	// size
+	"ar": true, "crop": true, "fit": true, "h": true, "max-h": true, "max-w": true, "min-h": true,
+	"min-w": true, "rect": true, "w": true,
+	// stylize
+	"blur": true, "duotone": true, "duotone-alpha": true, "htn": true, "monochrome": true, "px": true,
+	"sepia": true,
+	// text
+	"txt": true, "txt-align": true, "txt-clip": true, "txt-color": true, "txt-fit": true, "txt-font": true,
+	"txt-lead": true, "txt-line": true, "txt-line-color": true, "txt-pad": true, "txt-shad": true,
+	"txt-size": true, "txt-track": true, "txt-width": true, "txt-x": true, "txt-y": true,
+	// trim
+	"trim": true, "trim-color": true, "trim-md": true, "trim-pad": true, "trim-sd": true, "trim-tol": true,
+	// watermark
+	"mark": true, "mark-align": true, "mark-alpha": true, "mark-base": true, "mark-fit": true,
+	"mark-h": true, "mark-pad": true, "mark-rot": true, "mark-scale": true, "mark-tile": true,
+	"mark-w": true, "mark-x": true, "mark-y": true,
+	// animation
+	"frame": true, "loop": true, "reverse": true, "skip": true,
+	// expiration
+	"expires": true,
+}
+
+// isRenderingParam checks the catalog, parameters can also be passed base64 encoded with a 64 suffix, e.g. txt64
+func isRenderingParam(key string) bool {
+	return renderingParams[key] || (strings.HasSuffix(key, "64") && renderingParams[strings.TrimSuffix(key, "64")])
+}
@@ -0,0 +1,236 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"log"
+	"regexp"
+	"strings"
+)
+
+func resourceImgixAsset() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Allows managing metadata of assets in Imgix Asset Manager",
+		ReadContext:   resourceAssetRead,
+		CreateContext: resourceAssetCreate,
+		UpdateContext: resourceAssetUpdate,
+		DeleteContext: resourceAssetDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceAssetImport,
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: assetDescriptions["id"],
+			},
+			"source_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: assetDescriptions["source_id"],
+			},
+			"origin_path": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  assetDescriptions["origin_path"],
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^/.+"), "must start with a slash"),
+			},
+			"description": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: assetDescriptions["description"],
+			},
+			"tags": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: assetDescriptions["tags"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"categories": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Description: assetDescriptions["categories"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"custom_fields": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Description: assetDescriptions["custom_fields"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"content_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: assetDescriptions["content_type"],
+			},
+			"file_size": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: assetDescriptions["file_size"],
+			},
+			"media_width": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: assetDescriptions["media_width"],
+			},
+			"media_height": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: assetDescriptions["media_height"],
+			},
+			"date_created": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: assetDescriptions["date_created"],
+			},
+			"date_modified": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: assetDescriptions["date_modified"],
+			},
+		},
+	}
+}
+
+func resourceAssetRead(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	originPath := d.Get("origin_path").(string)
+
+	asset, err := c.getAsset(ctx, sourceId, originPath)
+	if err != nil {
+		return diag.Errorf("Error reading asset %s: %s", d.Id(), err.Error())
+	}
+
+	if asset == nil {
+		log.Printf("[WARN] Asset %s not found, removing from state", d.Id())
+		d.SetId("")
+		return nil
+	}
+
+	setResourceDataFieldsFromAsset(d, asset)
+	return nil
+}
+
+func setResourceDataFieldsFromAsset(d *schema.ResourceData, asset *Asset) {
+	customFields := make(map[string]interface{}, len(asset.Attributes.CustomFields))
+	for k, v := range asset.Attributes.CustomFields {
+		customFields[k] = fmt.Sprint(v)
+	}
+
+	d.Set("description", asset.Attributes.Description)
+	d.Set("tags", asset.Attributes.Tags)
+	d.Set("categories", asset.Attributes.Categories)
+	d.Set("custom_fields", customFields)
+	d.Set("content_type", asset.Attributes.ContentType)
+	d.Set("file_size", asset.Attributes.FileSize)
+	d.Set("media_width", asset.Attributes.MediaWidth)
+	d.Set("media_height", asset.Attributes.MediaHeight)
+	d.Set("date_created", asset.Attributes.DateCreated)
+	d.Set("date_modified", asset.Attributes.DateModified)
+}
+
+func resourceAssetCreate(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	originPath := d.Get("origin_path").(string)
+
+	existing, err := c.getAsset(ctx, sourceId, originPath)
+	if err != nil {
+		return diag.Errorf("Error reading asset %s%s: %s", sourceId, originPath, err.Error())
+	}
+
+	if existing == nil {
+		return diag.Errorf("Asset %s doesn't exist in source %s", originPath, sourceId)
+	}
+
+	if _, err = c.updateAsset(ctx, getAssetFromResourceData(d)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(sourceId + originPath)
+
+	return resourceAssetRead(ctx, d, i)
+}
+
+func resourceAssetUpdate(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	if _, err := c.updateAsset(ctx, getAssetFromResourceData(d)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceAssetRead(ctx, d, i)
+}
+
+func resourceAssetDelete(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	existing, err := c.getAsset(ctx, d.Get("source_id").(string), d.Get("origin_path").(string))
+	if err != nil {
+		return diag.Errorf("Error reading asset %s: %s", d.Id(), err.Error())
+	}
+
+	if existing == nil {
+		return nil
+	}
+
+	// assets can't be removed from Asset Manager, only their managed metadata is cleared
+	asset := &Asset{
+		Id:   String(d.Id()),
+		Type: String(TypeAsset),
+		Attributes: assetAttributes{
+			Categories:   []string{},
+			CustomFields: map[string]interface{}{},
+			OriginPath:   d.Get("origin_path").(string),
+			SourceId:     d.Get("source_id").(string),
+			Tags:         []string{},
+		},
+	}
+
+	if _, err = c.updateAsset(ctx, asset); err != nil {
+		return diag.Errorf("Error clearing metadata of asset %s: %s", d.Id(), err.Error())
+	}
+
+	return nil
+}
+
+func resourceAssetImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	sourceId, originPath, err := parseAssetId(d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	d.SetId(sourceId + originPath)
+	d.Set("source_id", sourceId)
+	d.Set("origin_path", originPath)
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// parseAssetId splits <source_id>/<origin_path> ids into source id and origin path with a leading slash
+func parseAssetId(id string) (string, string, error) {
+	i := strings.Index(id, "/")
+	if i <= 0 || i == len(id)-1 {
+		return "", "", fmt.Errorf("Invalid asset id %s, expected <source_id>/<origin_path>", id)
+	}
+
+	return id[:i], id[i:], nil
+}
+
+func getAssetFromResourceData(d *schema.ResourceData) *Asset {
+	asset := &Asset{}
+	asset.Id = String(d.Get("source_id").(string) + d.Get("origin_path").(string))
+	asset.Type = String(TypeAsset)
+	asset.Attributes.SourceId = d.Get("source_id").(string)
+	asset.Attributes.OriginPath = d.Get("origin_path").(string)
+	asset.Attributes.Description = StringNilIfEmpty(d.Get("description"))
+	asset.Attributes.Tags = SliceString(d.Get("tags").(*schema.Set).List())
+	asset.Attributes.Categories = SliceString(d.Get("categories").(*schema.Set).List())
+	asset.Attributes.CustomFields = d.Get("custom_fields").(map[string]interface{})
+
+	return asset
+}
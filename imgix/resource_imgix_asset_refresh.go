@@ -0,0 +1,237 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.opentelemetry.io/otel/attribute"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	assetStatusProcessing = "processing"
+	assetStatusReady      = "ready"
+	assetStatusFailed     = "failed"
+)
+
+func resourceImgixAssetRefresh() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Refreshes assets in Imgix Asset Manager whenever its triggers change",
+		ReadContext:   resourceAssetRefreshRead,
+		CreateContext: resourceAssetRefreshCreate,
+		DeleteContext: resourceAssetRefreshDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(time.Minute * 10),
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: assetRefreshDescriptions["id"],
+			},
+			"source_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: assetRefreshDescriptions["source_id"],
+			},
+			"origin_paths": {
+				Type:         schema.TypeSet,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  assetRefreshDescriptions["origin_paths"],
+				ExactlyOneOf: []string{"origin_paths", "path_prefix"},
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(regexp.MustCompile("^/.+"), "must start with a slash"),
+				},
+			},
+			"path_prefix": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				Description:  assetRefreshDescriptions["path_prefix"],
+				ExactlyOneOf: []string{"origin_paths", "path_prefix"},
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^/"), "must start with a slash"),
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				ForceNew:    true,
+				Description: assetRefreshDescriptions["triggers"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+			"refreshed_paths": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: assetRefreshDescriptions["refreshed_paths"],
+				Elem:        &schema.Schema{Type: schema.TypeString},
+			},
+		},
+	}
+}
+
+func resourceAssetRefreshRead(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// a refresh is a one-off action, there is nothing to read back
+	return nil
+}
+
+func resourceAssetRefreshCreate(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+
+	paths, err := getAssetRefreshPaths(ctx, c, d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	for _, path := range paths {
+		log.Printf("[DEBUG] Refreshing asset %s%s", sourceId, path)
+		if _, err = c.refreshAsset(ctx, sourceId, path); err != nil {
+			return diag.Errorf("Error refreshing asset %s%s: %s", sourceId, path, err.Error())
+		}
+	}
+
+	if err = waitForAssetsToBeRefreshed(ctx, c, sourceId, paths, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.Errorf("Error waiting for assets of source %s to be refreshed: %s", sourceId, err.Error())
+	}
+
+	d.SetId(resource.UniqueId())
+	d.Set("refreshed_paths", paths)
+
+	var diags diag.Diagnostics
+	if len(paths) == 0 {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "No assets were refreshed",
+			Detail:   fmt.Sprintf("Source %s has no assets under %s", sourceId, d.Get("path_prefix").(string)),
+		})
+	}
+	return append(diags, resourceAssetRefreshRead(ctx, d, i)...)
+}
+
+func resourceAssetRefreshDelete(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	return nil
+}
+
+// getAssetRefreshPaths returns the configured origin paths, or paths of all assets under the configured prefix
+func getAssetRefreshPaths(ctx context.Context, c *client, d *schema.ResourceData) ([]string, error) {
+	if v, ok := d.GetOk("origin_paths"); ok {
+		paths := SliceString(v.(*schema.Set).List())
+		sort.Strings(paths)
+		return paths, nil
+	}
+
+	sourceId := d.Get("source_id").(string)
+	prefix := d.Get("path_prefix").(string)
+	assets, err := c.listAssets(ctx, sourceId, map[string]string{"origin_path": prefix}, 100)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing assets of source %s: %s", sourceId, err.Error())
+	}
+
+	paths := []string{}
+	for _, a := range assets {
+		if strings.HasPrefix(a.Attributes.OriginPath, prefix) {
+			paths = append(paths, a.Attributes.OriginPath)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// assetRefreshDelay is how long to wait before checking refreshed assets, they don't start processing immediately
+var assetRefreshDelay = 5 * time.Second
+
+func waitForAssetsToBeRefreshed(ctx context.Context, c *client, sourceId string, paths []string, timeout time.Duration) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	log.Printf("[DEBUG] Waiting for %d assets of source %s being refreshed", len(paths), sourceId)
+	ctx, span := startSpan(
+		ctx,
+		"imgix.wait_for_assets_refreshed",
+		attribute.String("imgix.resource_id", sourceId),
+		attribute.Int("imgix.asset_count", len(paths)),
+		attribute.String("imgix.timeout", timeout.String()),
+	)
+
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{assetStatusProcessing},
+		Target:  []string{assetStatusReady},
+		Refresh: assetsStateRefreshFunc(ctx, c, sourceId, paths),
+		Delay:   assetRefreshDelay,
+		Timeout: timeout,
+	}
+
+	start := time.Now()
+	_, err := stateConf.WaitForStateContext(ctx)
+	span.SetAttributes(attribute.Int64("imgix.wait_duration_ms", time.Since(start).Milliseconds()))
+	endSpan(span, err)
+	return err
+}
+
+// assetsStateRefreshFunc lists the assets under the common prefix of the paths at once instead of getting every asset
+func assetsStateRefreshFunc(ctx context.Context, c *client, sourceId string, paths []string) resource.StateRefreshFunc {
+	prefix := commonPrefix(paths)
+
+	return func() (result interface{}, state string, err error) {
+		assets, err := c.listAssets(ctx, sourceId, map[string]string{"origin_path": prefix}, 100)
+		if err != nil {
+			return nil, "", err
+		}
+
+		statuses := make(map[string]*string, len(assets))
+		for _, a := range assets {
+			statuses[a.Attributes.OriginPath] = a.Attributes.Status
+		}
+
+		processing := 0
+		for _, path := range paths {
+			status, ok := statuses[path]
+			if !ok {
+				return nil, "", fmt.Errorf("asset %s%s not found", sourceId, path)
+			}
+
+			if status == nil {
+				continue
+			}
+
+			switch *status {
+			case assetStatusFailed:
+				return nil, "", fmt.Errorf("processing of asset %s%s failed", sourceId, path)
+			case assetStatusProcessing:
+				processing++
+			}
+		}
+
+		log.Printf("[TRACE] %d of %d assets of source %s are processing", processing, len(paths), sourceId)
+
+		if processing > 0 {
+			return paths, assetStatusProcessing, nil
+		}
+		return paths, assetStatusReady, nil
+	}
+}
+
+// commonPrefix returns the longest prefix shared by all values
+func commonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,158 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+	"time"
+)
+
+func skipAssetRefreshDelay(t *testing.T) {
+	delay := assetRefreshDelay
+	assetRefreshDelay = 0
+	t.Cleanup(func() { assetRefreshDelay = delay })
+}
+
+func TestWaitingForAssetsToBeRefreshedTimesOut(t *testing.T) {
+	skipAssetRefreshDelay(t)
+	c, api := prepareFakeApiTest(t, fakeimgix.WithDeployDelay(time.Hour))
+	sourceId := api.SeedSource(map[string]interface{}{
+		"name": "assets",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"assets"},
+		},
+	})
+	api.SeedAsset(sourceId, "/image.jpg", map[string]interface{}{})
+
+	if _, err := c.refreshAsset(context.Background(), sourceId, "/image.jpg"); err != nil {
+		t.Fatalf("response error should be nil: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	if err := waitForAssetsToBeRefreshed(ctx, c, sourceId, []string{"/image.jpg"}, time.Minute); err == nil {
+		t.Error("waiting should stop when the context is done")
+	}
+}
+
+func TestWaitingForAssetsToBeRefreshedListsAssets(t *testing.T) {
+	skipAssetRefreshDelay(t)
+	spans := recordSpans(t)
+	c, api := prepareFakeApiTest(t)
+	sourceId := api.SeedSource(map[string]interface{}{
+		"name": "assets",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"assets"},
+		},
+	})
+	paths := []string{"/images/hero.jpg", "/images/thumb.jpg", "/videos/intro.mp4"}
+	for _, path := range paths {
+		api.SeedAsset(sourceId, path, map[string]interface{}{})
+	}
+
+	if err := waitForAssetsToBeRefreshed(context.Background(), c, sourceId, paths, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	// statuses of all assets come from one list request
+	if recorded := spans.GetSpans(); len(recorded) != 2 {
+		t.Errorf("expected one request and the waiter span, got %d spans", len(recorded))
+	}
+
+	if err := waitForAssetsToBeRefreshed(context.Background(), c, sourceId, []string{"/images/missing.jpg"}, time.Minute); err == nil {
+		t.Error("waiting for a missing asset should fail")
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	cases := map[string][]string{
+		"":                 nil,
+		"/images/hero.jpg": {"/images/hero.jpg"},
+		"/images/":         {"/images/hero.jpg", "/images/thumb.jpg"},
+		"/":                {"/images/hero.jpg", "/videos/intro.mp4"},
+	}
+
+	for expected, values := range cases {
+		if prefix := commonPrefix(values); prefix != expected {
+			t.Errorf("common prefix of %v should be %q, got %q", values, expected, prefix)
+		}
+	}
+}
+
+func TestAccImgixAssetRefresh_basic(t *testing.T) {
+	skipAssetRefreshDelay(t)
+	api := startFakeApi(t, fakeimgix.WithDeployDelay(time.Second))
+	sourceId := api.SeedSource(map[string]interface{}{
+		"name": "assets",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"assets"},
+		},
+	})
+	api.SeedAsset(sourceId, "/images/hero.jpg", map[string]interface{}{})
+	api.SeedAsset(sourceId, "/images/thumb.jpg", map[string]interface{}{})
+	api.SeedAsset(sourceId, "/videos/intro.mp4", map[string]interface{}{})
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixAssetRefreshConfig(sourceId, "v1", `origin_paths = ["/images/hero.jpg"]`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_asset_refresh.test", "refreshed_paths.#", "1"),
+					testAccCheckImgixAssetRefreshes(api, sourceId, "/images/hero.jpg", 1),
+					testAccCheckImgixAssetRefreshes(api, sourceId, "/images/thumb.jpg", 0),
+				),
+			},
+			{
+				Config: testAccImgixAssetRefreshConfig(sourceId, "v2", `origin_paths = ["/images/hero.jpg"]`),
+				Check:  testAccCheckImgixAssetRefreshes(api, sourceId, "/images/hero.jpg", 2),
+			},
+			{
+				Config: testAccImgixAssetRefreshConfig(sourceId, "v2", `path_prefix = "/images/"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_asset_refresh.test", "refreshed_paths.#", "2"),
+					resource.TestCheckResourceAttr("imgix_asset_refresh.test", "refreshed_paths.0", "/images/hero.jpg"),
+					resource.TestCheckResourceAttr("imgix_asset_refresh.test", "refreshed_paths.1", "/images/thumb.jpg"),
+					testAccCheckImgixAssetRefreshes(api, sourceId, "/videos/intro.mp4", 0),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckImgixAssetRefreshes(api *fakeimgix.Server, sourceId, originPath string, expected int) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		if refreshes := api.Refreshes(sourceId, originPath); refreshes != expected {
+			return fmt.Errorf("asset %s%s should be refreshed %d times, got %d", sourceId, originPath, expected, refreshes)
+		}
+
+		if attributes, _ := api.Asset(sourceId, originPath); attributes["status"] != "ready" {
+			return fmt.Errorf("asset %s%s should be ready, got %v", sourceId, originPath, attributes["status"])
+		}
+		return nil
+	}
+}
+
+func testAccImgixAssetRefreshConfig(sourceId, version, paths string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_asset_refresh" "test" {
+  source_id = %[2]q
+  %[4]s
+
+  triggers = {
+    version = %[3]q
+  }
+}
+`, fakeimgix.DefaultApiKey, sourceId, version, paths)
+}
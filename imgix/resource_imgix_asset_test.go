@@ -0,0 +1,120 @@
+package imgix
+
+import (
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestParsingAssetId(t *testing.T) {
+	cases := map[string][]string{
+		"abc/image.jpg":        {"abc", "/image.jpg"},
+		"abc/nested/image.jpg": {"abc", "/nested/image.jpg"},
+		"abc":                  nil,
+		"abc/":                 nil,
+		"/image.jpg":           nil,
+	}
+
+	for id, expected := range cases {
+		t.Run(id, func(t *testing.T) {
+			sourceId, originPath, err := parseAssetId(id)
+			if expected == nil {
+				if err == nil {
+					t.Errorf("Asset id %s should be invalid", id)
+				}
+				return
+			}
+
+			if err != nil || sourceId != expected[0] || originPath != expected[1] {
+				t.Errorf("Asset id %s parsed to %s, %s, %v", id, sourceId, originPath, err)
+			}
+		})
+	}
+}
+
+func TestAccImgixAsset_basic(t *testing.T) {
+	api := startFakeApi(t)
+	sourceId := api.SeedSource(map[string]interface{}{
+		"name": "assets",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"assets"},
+		},
+	})
+	api.SeedAsset(sourceId, "/images/hero.jpg", map[string]interface{}{
+		"custom_fields": map[string]interface{}{"unmanaged": "value"},
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		CheckDestroy:      testAccCheckImgixAssetCleared(api, sourceId, "/images/hero.jpg"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixAssetConfig(sourceId, `
+  description = "Hero image"
+  tags        = ["hero", "summer"]
+  categories  = ["homepage"]
+  custom_fields = {
+    owner = "marketing"
+  }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_asset.test", "id", sourceId+"/images/hero.jpg"),
+					resource.TestCheckResourceAttr("imgix_asset.test", "description", "Hero image"),
+					resource.TestCheckResourceAttr("imgix_asset.test", "tags.#", "2"),
+					resource.TestCheckResourceAttr("imgix_asset.test", "categories.#", "1"),
+					resource.TestCheckResourceAttr("imgix_asset.test", "custom_fields.%", "1"),
+					resource.TestCheckResourceAttr("imgix_asset.test", "custom_fields.owner", "marketing"),
+					resource.TestCheckResourceAttr("imgix_asset.test", "content_type", "image/jpeg"),
+				),
+			},
+			{
+				Config: testAccImgixAssetConfig(sourceId, `
+  tags = ["hero"]
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_asset.test", "description", ""),
+					resource.TestCheckResourceAttr("imgix_asset.test", "tags.#", "1"),
+					resource.TestCheckResourceAttr("imgix_asset.test", "categories.#", "0"),
+					resource.TestCheckResourceAttr("imgix_asset.test", "custom_fields.%", "0"),
+				),
+			},
+			{
+				ResourceName:      "imgix_asset.test",
+				ImportState:       true,
+				ImportStateId:     sourceId + "/images/hero.jpg",
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckImgixAssetCleared(api *fakeimgix.Server, sourceId, originPath string) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		attributes, ok := api.Asset(sourceId, originPath)
+		if !ok {
+			return fmt.Errorf("asset %s%s should still exist", sourceId, originPath)
+		}
+
+		if len(attributes["tags"].([]interface{})) != 0 || len(attributes["custom_fields"].(map[string]interface{})) != 0 {
+			return fmt.Errorf("asset %s%s metadata was not cleared: %v", sourceId, originPath, attributes)
+		}
+		return nil
+	}
+}
+
+func testAccImgixAssetConfig(sourceId, fields string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_asset" "test" {
+  source_id   = %[2]q
+  origin_path = "/images/hero.jpg"
+%[3]s
+}
+`, fakeimgix.DefaultApiKey, sourceId, fields)
+}
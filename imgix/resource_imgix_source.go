@@ -8,10 +8,22 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"go.opentelemetry.io/otel/attribute"
 	"log"
+	"net/http"
+	"os"
+	"strings"
 	"time"
 )
 
+const (
+	sourceImportNamePrefix      = "name:"
+	sourceImportSubdomainPrefix = "subdomain:"
+
+	// S3 secret is never returned by the API, so it can only be passed to import out of band
+	importS3SecretKeyEnv = "IMGIX_IMPORT_S3_SECRET_KEY"
+)
+
 func resourceImgixSource() *schema.Resource {
 	return &schema.Resource{
 		Description:   "Allows managing Imgix sources",
@@ -19,12 +31,27 @@ func resourceImgixSource() *schema.Resource {
 		UpdateContext: resourceSourceUpdate,
 		CreateContext: resourceSourceCreate,
 		DeleteContext: resourceSourceDelete,
+		CustomizeDiff: resourceSourceCustomizeDiff,
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(time.Minute * 30),
 			Update: schema.DefaultTimeout(time.Minute * 30),
+			Delete: schema.DefaultTimeout(time.Minute * 30),
 		},
 		Importer: &schema.ResourceImporter{
-			StateContext: schema.ImportStatePassthroughContext,
+			StateContext: resourceSourceImport,
+		},
+		SchemaVersion: 2,
+		StateUpgraders: []schema.StateUpgrader{
+			{
+				Version: 0,
+				Type:    resourceImgixSourceV0().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceImgixSourceStateUpgradeV0,
+			},
+			{
+				Version: 1,
+				Type:    resourceImgixSourceV1().CoreConfigSchema().ImpliedType(),
+				Upgrade: resourceImgixSourceStateUpgradeV1,
+			},
 		},
 		Schema: map[string]*schema.Schema{
 			"id": {
@@ -69,6 +96,81 @@ func resourceImgixSource() *schema.Resource {
 				Default:     true,
 				Description: sourceDescriptions["wait_for_deployed"],
 			},
+			"ignore_custom_domains": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: sourceDescriptions["ignore_custom_domains"],
+			},
+			"verify_dns": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: sourceDescriptions["verify_dns"],
+			},
+			"ignore_default_params": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: sourceDescriptions["ignore_default_params"],
+			},
+			"preflight": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: sourceDescriptions["preflight"],
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"probe_object": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: sourceDescriptions["preflight_probe_object"],
+						},
+					},
+				},
+			},
+			"health_check": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: sourceDescriptions["health_check"],
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"path": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: sourceHealthCheckDescriptions["path"],
+						},
+						"expected_status": {
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      http.StatusOK,
+							Description:  sourceHealthCheckDescriptions["expected_status"],
+							ValidateFunc: validation.IntBetween(100, 599),
+						},
+						"expected_content_type": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Default:     "image/",
+							Description: sourceHealthCheckDescriptions["expected_content_type"],
+						},
+						"timeout": {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          "5m",
+							Description:      sourceHealthCheckDescriptions["timeout"],
+							ValidateDiagFunc: validateDuration,
+						},
+						"warn_only": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: sourceHealthCheckDescriptions["warn_only"],
+						},
+					},
+				},
+			},
+			"effective_deployment": effectiveDeploymentSchema(),
 			"deployment": {
 				Type:     schema.TypeList,
 				Required: true,
@@ -88,7 +190,7 @@ func resourceImgixSource() *schema.Resource {
 						"cache_ttl_behavior": {
 							Type:        schema.TypeString,
 							Optional:    true,
-							Default:     "respect_origin",
+							Computed:    true,
 							Description: sourceDescriptions["cache_ttl_behavior"],
 							ValidateFunc: validation.StringInSlice([]string{
 								"respect_origin",
@@ -99,14 +201,14 @@ func resourceImgixSource() *schema.Resource {
 						"cache_ttl_error": {
 							Type:         schema.TypeInt,
 							Optional:     true,
-							Default:      300,
+							Computed:     true,
 							Description:  sourceDescriptions["cache_ttl_error"],
 							ValidateFunc: validation.IntBetween(1, 31536000),
 						},
 						"cache_ttl_value": {
 							Type:         schema.TypeInt,
 							Optional:     true,
-							Default:      31536000,
+							Computed:     true,
 							Description:  sourceDescriptions["cache_ttl_value"],
 							ValidateFunc: validation.IntBetween(1, 31536000),
 						},
@@ -133,6 +235,7 @@ func resourceImgixSource() *schema.Resource {
 						"image_error": {
 							Type:        schema.TypeString,
 							Optional:    true,
+							Computed:    true,
 							Description: sourceDescriptions["image_error"],
 						},
 						"image_error_append_qs": {
@@ -165,6 +268,7 @@ func resourceImgixSource() *schema.Resource {
 						"secure_url_enabled": {
 							Type:        schema.TypeBool,
 							Optional:    true,
+							Computed:    true,
 							Description: sourceDescriptions["secure_url_enabled"],
 						},
 						"type": {
@@ -175,6 +279,7 @@ func resourceImgixSource() *schema.Resource {
 								"azure",
 								"gcs",
 								"s3",
+								"s3_compatible",
 								"webfolder",
 								"webproxy",
 							}, false),
@@ -200,6 +305,23 @@ func resourceImgixSource() *schema.Resource {
 							Optional:    true,
 							Description: sourceDescriptions["s3_prefix"],
 						},
+						"s3_endpoint": {
+							Type:         schema.TypeString,
+							Optional:     true,
+							Description:  sourceDescriptions["s3_endpoint"],
+							ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+						},
+						"s3_region": {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: sourceDescriptions["s3_region"],
+						},
+						"s3_path_style": {
+							Type:        schema.TypeBool,
+							Optional:    true,
+							Default:     false,
+							Description: sourceDescriptions["s3_path_style"],
+						},
 					},
 				},
 			},
@@ -207,26 +329,150 @@ func resourceImgixSource() *schema.Resource {
 	}
 }
 
-func resourceSourceRead(_ context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
-	c := i.(*client)
+func resourceSourceRead(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	_, diags := readSource(ctx, d, i.(*client))
+	return diags
+}
+
+// readSource sets the state of the source and returns it as read from the API,
+// including the custom domains and default params which are left out of state when ignored
+func readSource(ctx context.Context, d *schema.ResourceData, c *client) (*Source, diag.Diagnostics) {
 	var sourceRaw interface{}
 	var err error
 
 	if d.Get("wait_for_deployed").(bool) {
-		sourceRaw, err = waitForSourceToBeDeployed(c, d.Id(), d.Timeout(schema.TimeoutRead))
+		sourceRaw, err = waitForSourceToSettle(ctx, c, d.Id(), d.Timeout(schema.TimeoutRead))
 	} else {
-		sourceRaw, _, err = sourceStateRefreshFunc(c, d.Id())()
+		sourceRaw, _, err = sourceStateRefreshFunc(ctx, c, d.Id())()
 	}
 
 	if err != nil {
-		return diag.Errorf("Error reading source: %s", err.Error())
+		return nil, diag.Errorf("Error reading source: %s", err.Error())
 	}
 
 	source := sourceRaw.(*Source)
+	prior := sourceDeploymentFromResourceData(d)
 	setResourceDataFieldsFromSource(d, source)
+	setSourceDefaultsFields(d, source, prior, c.sourceDefaults)
+
+	deployment := d.Get("deployment").([]interface{})[0].(map[string]interface{})
+	if d.Get("ignore_custom_domains").(bool) {
+		deployment["custom_domains"] = []interface{}{}
+	}
+	if d.Get("ignore_default_params").(bool) {
+		deployment["default_params"] = map[string]interface{}{}
+	}
+	d.Set("deployment", []interface{}{deployment})
+	return source, nil
+}
+
+func resourceSourceCustomizeDiff(_ context.Context, d *schema.ResourceDiff, i interface{}) error {
+	if d.Get("ignore_custom_domains").(bool) && len(d.Get("deployment.0.custom_domains").([]interface{})) > 0 {
+		return fmt.Errorf("deployment.custom_domains can't be set when ignore_custom_domains is enabled, use imgix_source_custom_domain resources instead")
+	}
+
+	if d.Get("ignore_default_params").(bool) && len(d.Get("deployment.0.default_params").(map[string]interface{})) > 0 {
+		return fmt.Errorf("deployment.default_params can't be set when ignore_default_params is enabled, use imgix_source_default_param resources instead")
+	}
+
+	if err := validateSourceDeploymentStorage(d); err != nil {
+		return err
+	}
+
+	if len(d.Get("health_check").([]interface{})) > 0 && !d.Get("wait_for_deployed").(bool) {
+		return fmt.Errorf("health_check requires wait_for_deployed, the source has to be deployed before it is checked")
+	}
+
+	if len(d.Get("preflight").([]interface{})) > 0 && d.NewValueKnown("deployment.0.type") {
+		if deploymentType := d.Get("deployment.0.type").(string); deploymentType != "s3" && deploymentType != "s3_compatible" {
+			return fmt.Errorf("preflight is only supported for s3 and s3_compatible deployments, not %s", deploymentType)
+		}
+	}
+
+	return customizeSourceDefaultsDiff(d, i.(*client).sourceDefaults)
+}
+
+// validateSourceDeploymentStorage checks the storage settings required by the deployment type, the S3-compatible
+// endpoint options can't be used with other types
+func validateSourceDeploymentStorage(d *schema.ResourceDiff) error {
+	if !d.NewValueKnown("deployment.0.type") {
+		return nil
+	}
+
+	deploymentType := d.Get("deployment.0.type").(string)
+	if deploymentType == "s3_compatible" {
+		for _, key := range []string{"s3_endpoint", "s3_bucket", "s3_access_key"} {
+			if d.NewValueKnown("deployment.0."+key) && d.Get("deployment.0."+key).(string) == "" {
+				return fmt.Errorf("deployment.%s is required for s3_compatible deployments", key)
+			}
+		}
+		return nil
+	}
+
+	for _, key := range []string{"s3_endpoint", "s3_region"} {
+		if d.Get("deployment.0."+key).(string) != "" {
+			return fmt.Errorf("deployment.%s is only supported for s3_compatible deployments", key)
+		}
+	}
+	if d.Get("deployment.0.s3_path_style").(bool) {
+		return fmt.Errorf("deployment.s3_path_style is only supported for s3_compatible deployments")
+	}
+	return nil
+}
+
+// verifyCustomDomainsDns waits for custom domains added to the deployment to point at the source,
+// when verify_dns is enabled. Domains which were deployed before aren't checked again.
+func verifyCustomDomainsDns(ctx context.Context, d *schema.ResourceData, c *client, timeout time.Duration) error {
+	if !d.Get("verify_dns").(bool) {
+		return nil
+	}
+
+	oldRaw, newRaw := d.GetChange("deployment.0.custom_domains")
+	deployed := SliceString(oldRaw)
+	targets := customDomainCnameTargets(SliceString(d.Get("deployment.0.imgix_subdomains")))
+
+	for _, domain := range SliceString(newRaw) {
+		if d.Id() != "" && containsAllStrings(deployed, []string{domain}) {
+			continue
+		}
+
+		if err := waitForCustomDomainDns(ctx, c, domain, targets, timeout); err != nil {
+			return fmt.Errorf("Error verifying DNS of custom domain: %s", err.Error())
+		}
+	}
 	return nil
 }
 
+// keepExternallyManaged replaces custom domains and default params of the source with the ones currently deployed,
+// when they are managed by imgix_source_custom_domain or imgix_source_default_param resources. The returned function
+// releases the source lock held in the meantime.
+func keepExternallyManaged(ctx context.Context, d *schema.ResourceData, c *client, source *Source) (func(), error) {
+	ignoreDomains := d.Get("ignore_custom_domains").(bool)
+	ignoreParams := d.Get("ignore_default_params").(bool)
+	if !ignoreDomains && !ignoreParams {
+		return func() {}, nil
+	}
+
+	c.sourceLocks.Lock(*source.Id)
+	unlock := func() { c.sourceLocks.Unlock(*source.Id) }
+
+	current, err := c.getSourceById(ctx, *source.Id)
+	if err != nil {
+		unlock()
+		return nil, fmt.Errorf("Error reading source %s: %s", *source.Id, err.Error())
+	}
+
+	if current != nil {
+		if ignoreDomains {
+			source.Attributes.Deployment.CustomDomains = current.Attributes.Deployment.CustomDomains
+		}
+		if ignoreParams {
+			source.Attributes.Deployment.DefaultParams = current.Attributes.Deployment.DefaultParams
+		}
+	}
+	return unlock, nil
+}
+
 func setResourceDataFieldsFromSource(d *schema.ResourceData, source *Source) {
 	d.SetId(*source.Id)
 	d.Set("name", source.Attributes.Name)
@@ -236,55 +482,195 @@ func setResourceDataFieldsFromSource(d *schema.ResourceData, source *Source) {
 	d.Set("enabled", source.Attributes.Enabled)
 	d.Set("secure_url_token", source.Attributes.SecureUrlToken)
 
-	deployment := map[string]interface{}{}
+	deployment := flattenSourceDeployment(source.Attributes.Deployment)
 	if deploymentRaw, ok := d.GetOk("deployment"); ok {
-		if deploymentRaw != nil {
-			deployment = deploymentRaw.([]interface{})[0].(map[string]interface{})
+		current := deploymentRaw.([]interface{})[0].(map[string]interface{})
+		if secretKey, ok := current["s3_secret_key"]; ok {
+			deployment["s3_secret_key"] = secretKey
 		}
 	}
 
-	deployment["allows_upload"] = source.Attributes.Deployment.AllowsUpload
-	deployment["annotation"] = source.Attributes.Deployment.Annotation
-	deployment["cache_ttl_behavior"] = source.Attributes.Deployment.CacheTtlBehavior
-	deployment["cache_ttl_error"] = source.Attributes.Deployment.CacheTtlError
-	deployment["cache_ttl_value"] = source.Attributes.Deployment.CacheTtlValue
-	deployment["crossdomain_xml_enabled"] = source.Attributes.Deployment.CrossdomainXmlEnabled
-	deployment["custom_domains"] = source.Attributes.Deployment.CustomDomains
-	deployment["default_params"] = source.Attributes.Deployment.DefaultParams
-	deployment["image_error"] = source.Attributes.Deployment.ImageError
-	deployment["image_error_append_qs"] = source.Attributes.Deployment.ImageErrorAppendQs
-	deployment["image_missing"] = source.Attributes.Deployment.ImageMissing
-	deployment["image_missing_append_qs"] = source.Attributes.Deployment.ImageMissingAppendQs
-	deployment["imgix_subdomains"] = source.Attributes.Deployment.ImgixSubdomains
-	deployment["secure_url_enabled"] = source.Attributes.Deployment.SecureUrlEnabled
-	deployment["type"] = source.Attributes.Deployment.Type
-	deployment["s3_access_key"] = source.Attributes.Deployment.S3AccessKey
-	deployment["s3_bucket"] = source.Attributes.Deployment.S3Bucket
-	deployment["s3_prefix"] = source.Attributes.Deployment.S3Prefix
+	d.Set("deployment", []interface{}{deployment})
+}
 
+// setSourceDefaultsFields sets the effective deployment and removes default params coming from the provider
+// source_defaults from the deployment, prior is the deployment in state before the source was read
+func setSourceDefaultsFields(d *schema.ResourceData, source *Source, prior map[string]interface{}, defaults *sourceDefaults) {
+	var priorParams, priorEffectiveParams map[string]interface{}
+	if prior != nil {
+		priorParams, _ = prior["default_params"].(map[string]interface{})
+	}
+	if effective := d.Get("effective_deployment").([]interface{}); len(effective) == 1 && effective[0] != nil {
+		priorEffectiveParams, _ = effective[0].(map[string]interface{})["default_params"].(map[string]interface{})
+	}
+
+	effective := normalizeFlattenedDeployment(flattenSourceDeployment(source.Attributes.Deployment))
+	d.Set("effective_deployment", effectiveDeployment(effective))
+
+	deployment := d.Get("deployment").([]interface{})[0].(map[string]interface{})
+	params := deployment["default_params"].(map[string]interface{})
+	deployment["default_params"] = defaults.unmergeDefaultParams(params, priorParams, priorEffectiveParams)
+	d.Set("deployment", []interface{}{deployment})
+}
+
+// sourceDeploymentFromResourceData returns nil when there is no deployment in state
+func sourceDeploymentFromResourceData(d *schema.ResourceData) map[string]interface{} {
+	deployments := d.Get("deployment").([]interface{})
+	if len(deployments) != 1 || deployments[0] == nil {
+		return nil
+	}
+	return deployments[0].(map[string]interface{})
+}
+
+func flattenSourceDeployment(deployment sourceDeployment) map[string]interface{} {
+	// default_params is a map of strings in the schema, but the API may return any JSON value
+	defaultParams := make(map[string]interface{}, len(deployment.DefaultParams))
+	for k, v := range deployment.DefaultParams {
+		defaultParams[k] = fmt.Sprint(v)
+	}
+
+	return map[string]interface{}{
+		"allows_upload":           deployment.AllowsUpload,
+		"annotation":              deployment.Annotation,
+		"cache_ttl_behavior":      deployment.CacheTtlBehavior,
+		"cache_ttl_error":         deployment.CacheTtlError,
+		"cache_ttl_value":         deployment.CacheTtlValue,
+		"crossdomain_xml_enabled": deployment.CrossdomainXmlEnabled,
+		"custom_domains":          deployment.CustomDomains,
+		"default_params":          defaultParams,
+		"image_error":             deployment.ImageError,
+		"image_error_append_qs":   deployment.ImageErrorAppendQs,
+		"image_missing":           deployment.ImageMissing,
+		"image_missing_append_qs": deployment.ImageMissingAppendQs,
+		"imgix_subdomains":        deployment.ImgixSubdomains,
+		"secure_url_enabled":      deployment.SecureUrlEnabled,
+		"type":                    deployment.Type,
+		"s3_access_key":           deployment.S3AccessKey,
+		"s3_bucket":               deployment.S3Bucket,
+		"s3_prefix":               deployment.S3Prefix,
+		"s3_endpoint":             deployment.S3Endpoint,
+		"s3_region":               deployment.S3Region,
+		"s3_path_style":           deployment.S3PathStyle,
+	}
+}
+
+func resourceSourceImport(ctx context.Context, d *schema.ResourceData, i interface{}) ([]*schema.ResourceData, error) {
+	c := i.(*client)
+	id, err := resolveSourceImportId(ctx, c, d.Id())
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := c.getSourceById(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("Error importing source %s: %s", id, err.Error())
+	}
+
+	if source == nil || source.Id == nil {
+		return nil, fmt.Errorf("Error importing source %s: source not found", id)
+	}
+
+	setResourceDataFieldsFromSource(d, source)
+	setSourceDefaultsFields(d, source, nil, c.sourceDefaults)
+	d.Set("wait_for_deployed", true)
+	d.Set("ignore_custom_domains", false)
+	d.Set("ignore_default_params", false)
+	d.Set("verify_dns", false)
+
+	deployment := d.Get("deployment").([]interface{})[0].(map[string]interface{})
+	deployment["s3_secret_key"] = os.Getenv(importS3SecretKeyEnv)
 	d.Set("deployment", []interface{}{deployment})
+
+	return []*schema.ResourceData{d}, nil
+}
+
+// resolveSourceImportId translates name:<name> and subdomain:<subdomain> import ids into a source id.
+// Any other value is treated as a source id.
+func resolveSourceImportId(ctx context.Context, c *client, importId string) (string, error) {
+	var filters map[string]string
+	var matches func(s *Source) bool
+
+	switch {
+	case strings.HasPrefix(importId, sourceImportNamePrefix):
+		name := strings.TrimPrefix(importId, sourceImportNamePrefix)
+		filters = map[string]string{"name": name}
+		matches = func(s *Source) bool {
+			return s.Attributes.Name == name
+		}
+	case strings.HasPrefix(importId, sourceImportSubdomainPrefix):
+		subdomain := strings.TrimPrefix(importId, sourceImportSubdomainPrefix)
+		filters = map[string]string{"deployment.imgix_subdomains": subdomain}
+		matches = func(s *Source) bool {
+			for _, sd := range s.Attributes.Deployment.ImgixSubdomains {
+				if sd == subdomain {
+					return true
+				}
+			}
+			return false
+		}
+	default:
+		return importId, nil
+	}
+
+	sources, err := c.listSources(ctx, filters)
+	if err != nil {
+		return "", fmt.Errorf("Error listing sources for import %s: %s", importId, err.Error())
+	}
+
+	var ids []string
+	for _, s := range sources {
+		if s.Id != nil && matches(s) {
+			ids = append(ids, *s.Id)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return "", fmt.Errorf("No source found for import %s", importId)
+	case 1:
+		return ids[0], nil
+	default:
+		return "", fmt.Errorf("Multiple sources found for import %s: %s", importId, strings.Join(ids, ", "))
+	}
 }
 
 func resourceSourceUpdate(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
-	source, err := getSourceFromResourceData(d)
+	c := i.(*client)
+	source, err := getSourceFromResourceData(d, c.sourceDefaults)
 	if err != nil {
 		return diag.Errorf("Error reading source %s from state: %s", d.Id(), err.Error())
 	}
 
-	c := i.(*client)
+	if d.HasChanges("deployment", "preflight") {
+		if diags := preflightSourceOrigin(ctx, d, c); diags != nil {
+			return diags
+		}
+	}
+
+	if err = verifyCustomDomainsDns(ctx, d, c, d.Timeout(schema.TimeoutUpdate)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	unlock, err := keepExternallyManaged(ctx, d, c, source)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer unlock()
+
 	source, err = makeSourceRequest(ctx, func() (*Source, error) {
-		return c.updateSource(source)
+		return c.updateSource(ctx, source)
 	})
 
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	return resourceSourceRead(ctx, d, i)
+	return readAndCheckSourceHealth(ctx, d, c, schema.TimeoutUpdate)
 }
 
 func resourceSourceCreate(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
-	source, err := getSourceFromResourceData(d)
+	c := i.(*client)
+	source, err := getSourceFromResourceData(d, c.sourceDefaults)
 	if err != nil {
 		return diag.Errorf("Error reading source %s from state: %s", d.Id(), err.Error())
 	}
@@ -293,9 +679,16 @@ func resourceSourceCreate(ctx context.Context, d *schema.ResourceData, i interfa
 	source.Attributes.Enabled = nil
 	source.Type = String(TypeSource)
 
-	c := i.(*client)
+	if diags := preflightSourceOrigin(ctx, d, c); diags != nil {
+		return diags
+	}
+
+	if err = verifyCustomDomainsDns(ctx, d, c, d.Timeout(schema.TimeoutCreate)); err != nil {
+		return diag.FromErr(err)
+	}
+
 	newSource, err := makeSourceRequest(ctx, func() (*Source, error) {
-		return c.createSource(source)
+		return c.createSource(ctx, source)
 	})
 	if err != nil {
 		return diag.FromErr(err)
@@ -303,20 +696,57 @@ func resourceSourceCreate(ctx context.Context, d *schema.ResourceData, i interfa
 
 	d.SetId(*newSource.Id)
 
-	return resourceSourceRead(ctx, d, i)
+	// sources are always enabled when created, staged sources are disabled right away
+	if !d.Get("enabled").(bool) {
+		if err = c.disableSource(ctx, d.Id()); err != nil {
+			return diag.Errorf("Error disabling source %s after creation: %s", d.Id(), err.Error())
+		}
+	}
+
+	return readAndCheckSourceHealth(ctx, d, c, schema.TimeoutCreate)
+}
+
+// readAndCheckSourceHealth waits for the source to be deployed after a change, or disabled when it is disabled,
+// then reads it and runs the health check. Disabled sources aren't checked as they don't serve images.
+func readAndCheckSourceHealth(ctx context.Context, d *schema.ResourceData, c *client, timeoutKey string) diag.Diagnostics {
+	if d.Get("wait_for_deployed").(bool) {
+		status := expectedSourceStatus(d)
+		if _, err := waitForSourceStatus(ctx, c, d.Id(), status, d.Timeout(timeoutKey)); err != nil {
+			return diag.Errorf("Error waiting for source %s to be %s: %s", d.Id(), status, err.Error())
+		}
+	}
+
+	source, diags := readSource(ctx, d, c)
+	if diags.HasError() || d.Id() == "" || !d.Get("enabled").(bool) {
+		return diags
+	}
+	return append(diags, checkSourceHealth(ctx, d, c, source)...)
 }
 
-func resourceSourceDelete(_ context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+func resourceSourceDelete(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
 	c := i.(*client)
-	source, err := getSourceFromResourceData(d)
+	source, err := getSourceFromResourceData(d, c.sourceDefaults)
 	if err != nil {
 		return diag.FromErr(err)
 	}
 
-	if delErr := c.deleteSource(source); delErr != nil {
+	unlock, err := keepExternallyManaged(ctx, d, c, source)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	defer unlock()
+
+	if delErr := c.deleteSource(ctx, source); delErr != nil {
 		return diag.FromErr(delErr)
 	}
 
+	// resources the source serves from, like DNS records or buckets, can only be destroyed once it is disabled
+	if d.Get("wait_for_deployed").(bool) {
+		if _, err = waitForSourceStatus(ctx, c, d.Id(), "disabled", d.Timeout(schema.TimeoutDelete)); err != nil {
+			return diag.Errorf("Error waiting for source %s to be disabled: %s", d.Id(), err.Error())
+		}
+	}
+
 	return diag.Diagnostics{
 		{
 			Severity: diag.Warning,
@@ -326,6 +756,32 @@ func resourceSourceDelete(_ context.Context, d *schema.ResourceData, i interface
 	}
 }
 
+// modifySourceDeployment reads the deployment of a source and patches the attributes returned by modify.
+// The source lock is held, so concurrent changes of other values of the same attribute aren't lost.
+func modifySourceDeployment(ctx context.Context, c *client, sourceId string, modify func(deployment sourceDeployment) (map[string]interface{}, error)) error {
+	c.sourceLocks.Lock(sourceId)
+	defer c.sourceLocks.Unlock(sourceId)
+
+	source, err := c.getSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("Error reading source %s: %s", sourceId, err.Error())
+	}
+
+	if source == nil || source.Id == nil {
+		return fmt.Errorf("Source %s not found", sourceId)
+	}
+
+	changes, err := modify(source.Attributes.Deployment)
+	if err != nil {
+		return err
+	}
+
+	if err = c.patchSourceDeployment(ctx, sourceId, changes); err != nil {
+		return fmt.Errorf("Error updating source %s: %s", sourceId, err.Error())
+	}
+	return nil
+}
+
 func makeSourceRequest(ctx context.Context, operation func() (*Source, error)) (*Source, error) {
 	conf := &resource.StateChangeConf{
 		Pending: []string{"retry"},
@@ -350,7 +806,9 @@ func makeSourceRequest(ctx context.Context, operation func() (*Source, error)) (
 	return s, err
 }
 
-func getSourceFromResourceData(d *schema.ResourceData) (*Source, error) {
+// getSourceFromResourceData builds the source to send to the API, with the provider source defaults merged
+// into the deployment
+func getSourceFromResourceData(d *schema.ResourceData, defaults *sourceDefaults) (*Source, error) {
 	deploymentRaw := d.Get("deployment")
 	deployments := deploymentRaw.([]interface{})
 	if len(deployments) != 1 {
@@ -360,7 +818,11 @@ func getSourceFromResourceData(d *schema.ResourceData) (*Source, error) {
 		))
 	}
 
-	deployment := deployments[0].(map[string]interface{})
+	deployment := defaults.merge(deployments[0].(map[string]interface{}), configuredDeploymentKeys(d.GetRawConfig()))
+	if d.Get("ignore_default_params").(bool) {
+		// provider default params aren't applied to params managed by imgix_source_default_param resources
+		deployment["default_params"] = deployments[0].(map[string]interface{})["default_params"]
+	}
 	id := d.Id()
 	source := &Source{}
 	source.Id = &id
@@ -389,32 +851,88 @@ func getSourceFromResourceData(d *schema.ResourceData) (*Source, error) {
 	source.Attributes.Deployment.S3SecretKey = String(deployment["s3_secret_key"])
 	source.Attributes.Deployment.S3Bucket = String(deployment["s3_bucket"])
 	source.Attributes.Deployment.S3Prefix = String(deployment["s3_prefix"])
+	if source.Attributes.Deployment.Type == "s3_compatible" {
+		source.Attributes.Deployment.S3Endpoint = String(deployment["s3_endpoint"])
+		source.Attributes.Deployment.S3Region = StringNilIfEmpty(deployment["s3_region"])
+		source.Attributes.Deployment.S3PathStyle = Bool(deployment["s3_path_style"])
+	}
 
 	return source, nil
 }
 
-func waitForSourceToBeDeployed(client *client, id string, timeout time.Duration) (*Source, error) {
-	log.Printf("[DEBUG] Waiting for source %s being deployed", id)
+func waitForSourceToBeDeployed(ctx context.Context, client *client, id string, timeout time.Duration) (*Source, error) {
+	return waitForSourceStatus(ctx, client, id, "deployed", timeout)
+}
+
+// expectedSourceStatus is the deployment status a source reaches after a change, disabled sources don't deploy
+func expectedSourceStatus(d *schema.ResourceData) string {
+	return sourceStatus(d.Get("enabled").(bool))
+}
+
+// sourceStatus is the deployment status a source settles in depending on whether it's enabled
+func sourceStatus(enabled bool) string {
+	if !enabled {
+		return "disabled"
+	}
+	return "deployed"
+}
+
+// waitForSourceStatus waits after a change until the deployment status of the source is target, deployed or disabled
+func waitForSourceStatus(ctx context.Context, client *client, id, target string, timeout time.Duration) (*Source, error) {
+	pending := []string{"deploying"}
+	if target == "disabled" {
+		// a deployed source takes a while to stop serving
+		pending = append(pending, "deployed")
+	}
+
 	stateConf := &resource.StateChangeConf{
-		Pending: []string{"deploying"},
-		Target:  []string{"deployed"},
+		Pending: pending,
+		Target:  []string{target},
 		// source doesn't start deploying immediately after request is finished
 		Delay:   5 * time.Second,
-		Refresh: sourceStateRefreshFunc(client, id),
 		Timeout: timeout,
 	}
+	return waitForSource(ctx, client, id, stateConf, "imgix.wait_for_source_"+target)
+}
 
-	res, err := stateConf.WaitForStateContext(context.Background())
+// waitForSourceToSettle waits until a deployment in progress is finished. The source may be deployed or disabled,
+// it could have been enabled or disabled outside of Terraform.
+func waitForSourceToSettle(ctx context.Context, client *client, id string, timeout time.Duration) (*Source, error) {
+	stateConf := &resource.StateChangeConf{
+		Pending: []string{"deploying"},
+		Target:  []string{"deployed", "disabled"},
+		Timeout: timeout,
+	}
+	return waitForSource(ctx, client, id, stateConf, "imgix.wait_for_source_settled")
+}
+
+// waitForSource refreshes the source until stateConf is satisfied, tracing the wait in a span
+func waitForSource(ctx context.Context, client *client, id string, stateConf *resource.StateChangeConf, spanName string) (*Source, error) {
+	log.Printf("[DEBUG] Waiting for source %s being %s", id, strings.Join(stateConf.Target, " or "))
+	ctx, span := startSpan(
+		ctx,
+		spanName,
+		attribute.String("imgix.resource_id", id),
+		attribute.String("imgix.timeout", stateConf.Timeout.String()),
+	)
+
+	stateConf.Refresh = sourceStateRefreshFunc(ctx, client, id)
+	start := time.Now()
+	res, err := stateConf.WaitForStateContext(ctx)
 	var source *Source
 	if res != nil {
 		source = res.(*Source)
+		span.SetAttributes(attribute.String("imgix.deployment_status", *source.Attributes.DeploymentStatus))
 	}
+
+	span.SetAttributes(attribute.Int64("imgix.wait_duration_ms", time.Since(start).Milliseconds()))
+	endSpan(span, err)
 	return source, err
 }
 
-func sourceStateRefreshFunc(client *client, id string) resource.StateRefreshFunc {
+func sourceStateRefreshFunc(ctx context.Context, client *client, id string) resource.StateRefreshFunc {
 	return func() (result interface{}, state string, err error) {
-		source, err := client.getSourceById(id)
+		source, err := client.getSourceById(ctx, id)
 		if err != nil {
 			return nil, "", err
 		}
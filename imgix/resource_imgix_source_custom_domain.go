@@ -0,0 +1,156 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"log"
+	"strings"
+	"time"
+)
+
+func resourceImgixSourceCustomDomain() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Attaches a single custom domain to an Imgix source, independently of the imgix_source resource",
+		ReadContext:   resourceSourceCustomDomainRead,
+		CreateContext: resourceSourceCustomDomainCreate,
+		DeleteContext: resourceSourceCustomDomainDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceSourceCustomDomainImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(time.Minute * 30),
+			Delete: schema.DefaultTimeout(time.Minute * 30),
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceCustomDomainDescriptions["id"],
+			},
+			"source_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: sourceCustomDomainDescriptions["source_id"],
+			},
+			"domain": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				Description:      sourceCustomDomainDescriptions["domain"],
+				ValidateDiagFunc: validateCustomDomain,
+			},
+			"wait_for_deployed": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     true,
+				Description: sourceCustomDomainDescriptions["wait_for_deployed"],
+			},
+		},
+	}
+}
+
+func resourceSourceCustomDomainRead(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	domain := d.Get("domain").(string)
+
+	source, err := c.getSourceById(ctx, sourceId)
+	if err != nil {
+		return diag.Errorf("Error reading source %s: %s", sourceId, err.Error())
+	}
+
+	if source == nil || source.Id == nil || !containsAllStrings(source.Attributes.Deployment.CustomDomains, []string{domain}) {
+		log.Printf("[WARN] Custom domain %s of source %s not found, removing from state", domain, sourceId)
+		d.SetId("")
+		return nil
+	}
+
+	return nil
+}
+
+func resourceSourceCustomDomainCreate(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	domain := d.Get("domain").(string)
+
+	err := modifySourceCustomDomains(ctx, c, sourceId, func(domains []string) ([]string, error) {
+		if containsAllStrings(domains, []string{domain}) {
+			return nil, fmt.Errorf("Custom domain %s is already attached to source %s, import it to manage it", domain, sourceId)
+		}
+		return append(domains, domain), nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(sourceCustomDomainId(sourceId, domain))
+
+	if d.Get("wait_for_deployed").(bool) {
+		if _, err = waitForSourceToBeDeployed(ctx, c, sourceId, d.Timeout(schema.TimeoutCreate)); err != nil {
+			return diag.Errorf("Error waiting for source %s to be deployed: %s", sourceId, err.Error())
+		}
+	}
+
+	return resourceSourceCustomDomainRead(ctx, d, i)
+}
+
+func resourceSourceCustomDomainDelete(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	domain := d.Get("domain").(string)
+
+	err := modifySourceCustomDomains(ctx, c, sourceId, func(domains []string) ([]string, error) {
+		var kept []string
+		for _, existing := range domains {
+			if existing != domain {
+				kept = append(kept, existing)
+			}
+		}
+		return kept, nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.Get("wait_for_deployed").(bool) {
+		if _, err = waitForSourceToBeDeployed(ctx, c, sourceId, d.Timeout(schema.TimeoutDelete)); err != nil {
+			return diag.Errorf("Error waiting for source %s to be deployed: %s", sourceId, err.Error())
+		}
+	}
+	return nil
+}
+
+func resourceSourceCustomDomainImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("Invalid import id %s, expected <source_id>/<domain>", d.Id())
+	}
+
+	d.Set("source_id", parts[0])
+	d.Set("domain", parts[1])
+	d.Set("wait_for_deployed", true)
+	return []*schema.ResourceData{d}, nil
+}
+
+// modifySourceCustomDomains patches the custom domains of a source with the result of modify
+func modifySourceCustomDomains(ctx context.Context, c *client, sourceId string, modify func(domains []string) ([]string, error)) error {
+	return modifySourceDeployment(ctx, c, sourceId, func(deployment sourceDeployment) (map[string]interface{}, error) {
+		domains, err := modify(deployment.CustomDomains)
+		if err != nil {
+			return nil, err
+		}
+
+		if domains == nil {
+			domains = []string{}
+		}
+		return map[string]interface{}{"custom_domains": domains}, nil
+	})
+}
+
+func sourceCustomDomainId(sourceId, domain string) string {
+	return sourceId + "/" + domain
+}
@@ -0,0 +1,149 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"regexp"
+	"sort"
+	"sync"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestModifyingSourceCustomDomainsConcurrently(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+			"custom_domains":   []string{"existing.example.com"},
+		},
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			errs <- modifySourceCustomDomains(context.Background(), c, id, func(domains []string) ([]string, error) {
+				return append(domains, domain), nil
+			})
+		}(fmt.Sprintf("images-%d.example.com", i))
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source, err := c.getSourceById(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domains := source.Attributes.Deployment.CustomDomains
+	if len(domains) != 11 {
+		t.Errorf("all concurrently attached domains should be kept, got %v", domains)
+	}
+	if sort.Strings(domains); domains[0] != "existing.example.com" {
+		t.Errorf("existing domain should be kept, got %v", domains)
+	}
+}
+
+func TestAccImgixSourceCustomDomain_basic(t *testing.T) {
+	api := startFakeApi(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceCustomDomainConfig(`
+resource "imgix_source_custom_domain" "other" {
+  source_id = imgix_source.test.id
+  domain    = "b.example.com"
+}
+`, ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source_custom_domain.test", "domain", "a.example.com"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.custom_domains.#", "0"),
+					testAccCheckImgixSourceCustomDomains(api, "a.example.com", "b.example.com"),
+				),
+			},
+			{
+				// changing the source keeps the domains attached by imgix_source_custom_domain
+				Config: testAccImgixSourceCustomDomainConfig("", `annotation = "updated"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.annotation", "updated"),
+					testAccCheckImgixSourceCustomDomains(api, "a.example.com"),
+				),
+			},
+			{
+				ResourceName:      "imgix_source_custom_domain.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config:      testAccImgixSourceCustomDomainConfig("", `custom_domains = ["c.example.com"]`),
+				ExpectError: regexp.MustCompile("custom_domains can't be set when ignore_custom_domains is enabled"),
+			},
+		},
+	})
+}
+
+func testAccCheckImgixSourceCustomDomains(api *fakeimgix.Server, expected ...string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources["imgix_source.test"].Primary.ID
+		attributes, ok := api.Source(id)
+		if !ok {
+			return fmt.Errorf("source %s not found", id)
+		}
+
+		// domains are attached concurrently, so their order isn't stable
+		domains := toStringSlice(attributes["deployment"].(map[string]interface{})["custom_domains"])
+		sort.Strings(domains)
+		if fmt.Sprint(domains) != fmt.Sprint(expected) {
+			return fmt.Errorf("source %s custom_domains should be %v, got %v", id, expected, domains)
+		}
+		return nil
+	}
+}
+
+func testAccImgixSourceCustomDomainConfig(extraResources, extraDeployment string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_source" "test" {
+  name                  = "custom-domain-test"
+  ignore_custom_domains = true
+
+  deployment {
+    type             = "webfolder"
+    imgix_subdomains = ["custom-domain-test"]
+    %[3]s
+  }
+}
+
+resource "imgix_source_custom_domain" "test" {
+  source_id = imgix_source.test.id
+  domain    = "a.example.com"
+}
+%[2]s
+`, fakeimgix.DefaultApiKey, extraResources, extraDeployment)
+}
+
+func toStringSlice(v interface{}) []string {
+	var s []string
+	for _, i := range v.([]interface{}) {
+		s = append(s, i.(string))
+	}
+	return s
+}
@@ -0,0 +1,190 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"log"
+	"strings"
+	"time"
+)
+
+func resourceImgixSourceDefaultParam() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Sets a single default parameter of an Imgix source, independently of the imgix_source resource",
+		ReadContext:   resourceSourceDefaultParamRead,
+		CreateContext: resourceSourceDefaultParamCreate,
+		UpdateContext: resourceSourceDefaultParamUpdate,
+		DeleteContext: resourceSourceDefaultParamDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceSourceDefaultParamImport,
+		},
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(time.Minute * 30),
+			Update: schema.DefaultTimeout(time.Minute * 30),
+			Delete: schema.DefaultTimeout(time.Minute * 30),
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDefaultParamDescriptions["id"],
+			},
+			"source_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: sourceDefaultParamDescriptions["source_id"],
+			},
+			"key": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				Description:      sourceDefaultParamDescriptions["key"],
+				ValidateDiagFunc: validateRenderingParam,
+			},
+			"value": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: sourceDefaultParamDescriptions["value"],
+			},
+			"wait_for_deployed": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: sourceDefaultParamDescriptions["wait_for_deployed"],
+			},
+		},
+	}
+}
+
+func resourceSourceDefaultParamRead(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	key := d.Get("key").(string)
+
+	source, err := c.getSourceById(ctx, sourceId)
+	if err != nil {
+		return diag.Errorf("Error reading source %s: %s", sourceId, err.Error())
+	}
+
+	var value interface{}
+	found := false
+	if source != nil && source.Id != nil {
+		value, found = source.Attributes.Deployment.DefaultParams[key]
+	}
+
+	if !found {
+		log.Printf("[WARN] Default parameter %s of source %s not found, removing from state", key, sourceId)
+		d.SetId("")
+		return nil
+	}
+
+	d.Set("value", fmt.Sprint(value))
+	return nil
+}
+
+func resourceSourceDefaultParamCreate(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	key := d.Get("key").(string)
+
+	err := modifySourceDefaultParams(ctx, c, sourceId, func(params map[string]interface{}) error {
+		if _, ok := params[key]; ok {
+			return fmt.Errorf("Default parameter %s is already set on source %s, import it to manage it", key, sourceId)
+		}
+		params[key] = d.Get("value").(string)
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(sourceDefaultParamId(sourceId, key))
+
+	if diags := waitForDefaultParamDeployed(ctx, d, c, schema.TimeoutCreate); diags != nil {
+		return diags
+	}
+	return resourceSourceDefaultParamRead(ctx, d, i)
+}
+
+func resourceSourceDefaultParamUpdate(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	key := d.Get("key").(string)
+
+	if d.HasChange("value") {
+		err := modifySourceDefaultParams(ctx, c, sourceId, func(params map[string]interface{}) error {
+			params[key] = d.Get("value").(string)
+			return nil
+		})
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		if diags := waitForDefaultParamDeployed(ctx, d, c, schema.TimeoutUpdate); diags != nil {
+			return diags
+		}
+	}
+	return resourceSourceDefaultParamRead(ctx, d, i)
+}
+
+func resourceSourceDefaultParamDelete(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	key := d.Get("key").(string)
+
+	err := modifySourceDefaultParams(ctx, c, sourceId, func(params map[string]interface{}) error {
+		delete(params, key)
+		return nil
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return waitForDefaultParamDeployed(ctx, d, c, schema.TimeoutDelete)
+}
+
+func resourceSourceDefaultParamImport(_ context.Context, d *schema.ResourceData, _ interface{}) ([]*schema.ResourceData, error) {
+	parts := strings.SplitN(d.Id(), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("Invalid import id %s, expected <source_id>/<key>", d.Id())
+	}
+
+	d.Set("source_id", parts[0])
+	d.Set("key", parts[1])
+	d.Set("wait_for_deployed", true)
+	return []*schema.ResourceData{d}, nil
+}
+
+func waitForDefaultParamDeployed(ctx context.Context, d *schema.ResourceData, c *client, timeoutKey string) diag.Diagnostics {
+	if !d.Get("wait_for_deployed").(bool) {
+		return nil
+	}
+
+	sourceId := d.Get("source_id").(string)
+	if _, err := waitForSourceToBeDeployed(ctx, c, sourceId, d.Timeout(timeoutKey)); err != nil {
+		return diag.Errorf("Error waiting for source %s to be deployed: %s", sourceId, err.Error())
+	}
+	return nil
+}
+
+// modifySourceDefaultParams patches the default params of a source after modify changed them in place
+func modifySourceDefaultParams(ctx context.Context, c *client, sourceId string, modify func(params map[string]interface{}) error) error {
+	return modifySourceDeployment(ctx, c, sourceId, func(deployment sourceDeployment) (map[string]interface{}, error) {
+		params := make(map[string]interface{}, len(deployment.DefaultParams))
+		for k, v := range deployment.DefaultParams {
+			params[k] = v
+		}
+
+		if err := modify(params); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"default_params": params}, nil
+	})
+}
+
+func sourceDefaultParamId(sourceId, key string) string {
+	return sourceId + "/" + key
+}
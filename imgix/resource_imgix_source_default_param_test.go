@@ -0,0 +1,135 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"regexp"
+	"sync"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestModifyingSourceDefaultParamsConcurrently(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+			"default_params":   map[string]interface{}{"auto": "format"},
+		},
+	})
+
+	keys := []string{"w", "h", "q", "fit", "crop", "dpr"}
+	var wg sync.WaitGroup
+	errs := make(chan error, len(keys))
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			errs <- modifySourceDefaultParams(context.Background(), c, id, func(params map[string]interface{}) error {
+				params[key] = "1"
+				return nil
+			})
+		}(key)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	source, err := c.getSourceById(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := source.Attributes.Deployment.DefaultParams
+	if len(params) != len(keys)+1 || params["auto"] != "format" {
+		t.Errorf("all concurrently set params should be kept, got %v", params)
+	}
+}
+
+func TestAccImgixSourceDefaultParam_basic(t *testing.T) {
+	api := startFakeApi(t)
+	other := `
+resource "imgix_source_default_param" "other" {
+  source_id = imgix_source.test.id
+  key       = "q"
+  value     = "75"
+}
+`
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImgixSourceDefaultParamConfig("unknown", "1", "", ""),
+				ExpectError: regexp.MustCompile("Unknown imgix rendering parameter unknown"),
+			},
+			{
+				Config: testAccImgixSourceDefaultParamConfig("auto", "format", "", other),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.default_params.%", "0"),
+					testAccCheckImgixSourceDefaultParamsInState(api, map[string]interface{}{"auto": "format", "q": "75"}),
+				),
+			},
+			{
+				// changing the source keeps the params set by imgix_source_default_param
+				Config: testAccImgixSourceDefaultParamConfig("auto", "compress", `annotation = "updated"`, other),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source_default_param.test", "value", "compress"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.annotation", "updated"),
+					testAccCheckImgixSourceDefaultParamsInState(api, map[string]interface{}{"auto": "compress", "q": "75"}),
+				),
+			},
+			{
+				ResourceName:      "imgix_source_default_param.other",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				Config:      testAccImgixSourceDefaultParamConfig("auto", "compress", `default_params = { w = "100" }`, other),
+				ExpectError: regexp.MustCompile("default_params can't be set when ignore_default_params is enabled"),
+			},
+		},
+	})
+}
+
+func testAccCheckImgixSourceDefaultParamsInState(api *fakeimgix.Server, expected map[string]interface{}) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources["imgix_source.test"].Primary.ID
+		return testAccCheckImgixSourceDefaultParams(api, id, expected)(s)
+	}
+}
+
+func testAccImgixSourceDefaultParamConfig(key, value, extraDeployment, extraResources string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_source" "test" {
+  name                  = "default-param-test"
+  ignore_default_params = true
+
+  deployment {
+    type             = "webfolder"
+    imgix_subdomains = ["default-param-test"]
+    %[5]s
+  }
+}
+
+resource "imgix_source_default_param" "test" {
+  source_id = imgix_source.test.id
+  key       = %[2]q
+  value     = %[3]q
+}
+%[4]s
+`, fakeimgix.DefaultApiKey, key, value, extraResources, extraDeployment)
+}
@@ -0,0 +1,148 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"log"
+	"time"
+)
+
+// resourceImgixSourceV0 is the imgix_source schema before versioning was introduced.
+// It's only used to decode old states and must not be changed.
+func resourceImgixSourceV0() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"id":                {Type: schema.TypeString, Computed: true},
+			"type":              {Type: schema.TypeString, Computed: true},
+			"name":              {Type: schema.TypeString, Required: true},
+			"deployment_status": {Type: schema.TypeString, Computed: true},
+			"enabled":           {Type: schema.TypeBool, Optional: true},
+			"date_deployed":     {Type: schema.TypeInt, Computed: true},
+			"secure_url_token":  {Type: schema.TypeString, Computed: true},
+			"wait_for_deployed": {Type: schema.TypeBool, Optional: true},
+			"deployment": {
+				Type:     schema.TypeList,
+				Required: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"allows_upload":           {Type: schema.TypeBool, Computed: true},
+						"annotation":              {Type: schema.TypeString, Optional: true},
+						"cache_ttl_behavior":      {Type: schema.TypeString, Optional: true},
+						"cache_ttl_error":         {Type: schema.TypeInt, Optional: true},
+						"cache_ttl_value":         {Type: schema.TypeInt, Optional: true},
+						"crossdomain_xml_enabled": {Type: schema.TypeBool, Optional: true},
+						"custom_domains": {
+							Type:     schema.TypeList,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"default_params": {
+							Type:     schema.TypeMap,
+							Optional: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"image_error":             {Type: schema.TypeString, Optional: true},
+						"image_error_append_qs":   {Type: schema.TypeBool, Optional: true},
+						"image_missing":           {Type: schema.TypeString, Optional: true},
+						"image_missing_append_qs": {Type: schema.TypeBool, Optional: true},
+						"imgix_subdomains": {
+							Type:     schema.TypeList,
+							Required: true,
+							Elem:     &schema.Schema{Type: schema.TypeString},
+						},
+						"secure_url_enabled": {Type: schema.TypeBool, Optional: true},
+						"type":               {Type: schema.TypeString, Required: true},
+						"s3_access_key":      {Type: schema.TypeString, Optional: true},
+						"s3_secret_key":      {Type: schema.TypeString, Optional: true, Sensitive: true},
+						"s3_bucket":          {Type: schema.TypeString, Optional: true},
+						"s3_prefix":          {Type: schema.TypeString, Optional: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+// resourceImgixSourceStateUpgradeV0 fills in attributes which version 0 left empty,
+// mostly in imported states, so that they don't show up as changes in the first plan.
+func resourceImgixSourceStateUpgradeV0(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return nil, nil
+	}
+
+	log.Printf("[DEBUG] Upgrading imgix_source %v state from version 0", rawState["id"])
+
+	setDefaultStateValue(rawState, "enabled", true)
+	setDefaultStateValue(rawState, "wait_for_deployed", true)
+
+	deployments, _ := rawState["deployment"].([]interface{})
+	for i, raw := range deployments {
+		deployment, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Invalid deployment %d in imgix_source state", i)
+		}
+
+		setDefaultStateValue(deployment, "annotation", "")
+		setDefaultStateValue(deployment, "cache_ttl_behavior", "respect_origin")
+		setDefaultStateValue(deployment, "cache_ttl_error", 300)
+		setDefaultStateValue(deployment, "cache_ttl_value", 31536000)
+		setDefaultStateValue(deployment, "crossdomain_xml_enabled", false)
+		setDefaultStateValue(deployment, "custom_domains", []interface{}{})
+		setDefaultStateValue(deployment, "image_error_append_qs", false)
+		setDefaultStateValue(deployment, "image_missing_append_qs", false)
+
+		defaultParams, _ := deployment["default_params"].(map[string]interface{})
+		params := make(map[string]interface{}, len(defaultParams))
+		for k, v := range defaultParams {
+			params[k] = fmt.Sprint(v)
+		}
+		deployment["default_params"] = params
+	}
+
+	return rawState, nil
+}
+
+// resourceImgixSourceV1 is the imgix_source schema of version 1, it has the attributes of version 0 and timeouts.
+// It's only used to decode old states and must not be changed.
+func resourceImgixSourceV1() *schema.Resource {
+	r := resourceImgixSourceV0()
+	r.Timeouts = &schema.ResourceTimeout{
+		Create: schema.DefaultTimeout(time.Minute * 30),
+		Update: schema.DefaultTimeout(time.Minute * 30),
+	}
+	return r
+}
+
+// resourceImgixSourceStateUpgradeV1 fills in the defaults of the attributes added in version 2,
+// so that they don't show up as changes in the first plan after upgrading the provider.
+func resourceImgixSourceStateUpgradeV1(_ context.Context, rawState map[string]interface{}, _ interface{}) (map[string]interface{}, error) {
+	if rawState == nil {
+		return nil, nil
+	}
+
+	log.Printf("[DEBUG] Upgrading imgix_source %v state from version 1", rawState["id"])
+
+	setDefaultStateValue(rawState, "ignore_custom_domains", false)
+	setDefaultStateValue(rawState, "ignore_default_params", false)
+	setDefaultStateValue(rawState, "verify_dns", false)
+
+	deployments, _ := rawState["deployment"].([]interface{})
+	for i, raw := range deployments {
+		deployment, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("Invalid deployment %d in imgix_source state", i)
+		}
+
+		setDefaultStateValue(deployment, "s3_path_style", false)
+	}
+
+	return rawState, nil
+}
+
+func setDefaultStateValue(state map[string]interface{}, key string, value interface{}) {
+	if v, ok := state[key]; !ok || v == nil || v == "" {
+		state[key] = value
+	}
+}
@@ -0,0 +1,112 @@
+package imgix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	ctyjson "github.com/hashicorp/go-cty/cty/json"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var updateGoldenFiles = flag.Bool("update", false, "rewrite golden files of state upgrade tests")
+
+func TestResourceImgixSourceStateUpgrades(t *testing.T) {
+	testStateUpgradeGoldenFiles(t, resourceImgixSource(), "./testdata/state_upgrades/imgix_source")
+}
+
+// testStateUpgradeGoldenFiles upgrades every <dir>/v<version>/<case>.json state to the current schema
+// version and compares the result with <case>.golden.json. Run go test with -update to regenerate them.
+func testStateUpgradeGoldenFiles(t *testing.T, r *schema.Resource, dir string) {
+	fixtures, err := filepath.Glob(filepath.Join(dir, "v*", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fixtures) == 0 {
+		t.Fatalf("no state fixtures found in %s", dir)
+	}
+
+	for _, fixture := range fixtures {
+		if strings.HasSuffix(fixture, ".golden.json") {
+			continue
+		}
+
+		fixture := fixture
+		versionDir := filepath.Base(filepath.Dir(fixture))
+		t.Run(versionDir+"/"+filepath.Base(fixture), func(t *testing.T) {
+			version, err := strconv.Atoi(strings.TrimPrefix(versionDir, "v"))
+			if err != nil {
+				t.Fatalf("invalid version directory %s", versionDir)
+			}
+
+			raw, err := ioutil.ReadFile(fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			upgraded, err := upgradeStateFixture(r, version, raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := strings.TrimSuffix(fixture, ".json") + ".golden.json"
+			if *updateGoldenFiles {
+				if err := ioutil.WriteFile(golden, upgraded, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			expected, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("missing golden file, run tests with -update: %s", err)
+			}
+
+			if !bytes.Equal(upgraded, expected) {
+				t.Errorf("upgraded state doesnt match %s:\n%s", golden, upgraded)
+			}
+		})
+	}
+}
+
+func upgradeStateFixture(r *schema.Resource, version int, raw []byte) ([]byte, error) {
+	state := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, err
+	}
+
+	for _, upgrader := range r.StateUpgraders {
+		if upgrader.Version < version {
+			continue
+		}
+
+		if _, err := ctyjson.Unmarshal(raw, upgrader.Type); err != nil {
+			return nil, err
+		}
+
+		var err error
+		if state, err = upgrader.Upgrade(context.Background(), state, nil); err != nil {
+			return nil, err
+		}
+
+		if raw, err = json.Marshal(state); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := ctyjson.Unmarshal(raw, r.CoreConfigSchema().ImpliedType()); err != nil {
+		return nil, err
+	}
+
+	out, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
@@ -0,0 +1,205 @@
+package imgix
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"io/ioutil"
+	"log"
+	"regexp"
+)
+
+func resourceImgixSourceObject() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Allows uploading files to the origin of Imgix sources which allow uploads",
+		ReadContext:   resourceSourceObjectRead,
+		CreateContext: resourceSourceObjectPut,
+		UpdateContext: resourceSourceObjectPut,
+		DeleteContext: resourceSourceObjectDelete,
+		CustomizeDiff: resourceSourceObjectCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceObjectDescriptions["id"],
+			},
+			"source_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: sourceObjectDescriptions["source_id"],
+			},
+			"origin_path": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				Description:  sourceObjectDescriptions["origin_path"],
+				ValidateFunc: validation.StringMatch(regexp.MustCompile("^/.+"), "must start with a slash"),
+			},
+			"source": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  sourceObjectDescriptions["source"],
+				ExactlyOneOf: []string{"source", "content_base64"},
+			},
+			"content_base64": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Description:  sourceObjectDescriptions["content_base64"],
+				ValidateFunc: validation.StringIsBase64,
+				ExactlyOneOf: []string{"source", "content_base64"},
+			},
+			"content_type": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: sourceObjectDescriptions["content_type"],
+			},
+			"content_hash": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceObjectDescriptions["content_hash"],
+			},
+			"asset_id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceObjectDescriptions["asset_id"],
+			},
+		},
+	}
+}
+
+// resourceGetter is implemented by both schema.ResourceData and schema.ResourceDiff
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+func readSourceObjectContent(d resourceGetter) ([]byte, error) {
+	if path := d.Get("source").(string); path != "" {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading %s: %s", path, err.Error())
+		}
+		return content, nil
+	}
+
+	content, err := base64.StdEncoding.DecodeString(d.Get("content_base64").(string))
+	if err != nil {
+		return nil, fmt.Errorf("Error decoding content_base64: %s", err.Error())
+	}
+	return content, nil
+}
+
+func sourceObjectContentHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+func resourceSourceObjectCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, i interface{}) error {
+	if d.NewValueKnown("source") && d.NewValueKnown("content_base64") {
+		content, err := readSourceObjectContent(d)
+		if err != nil {
+			return err
+		}
+
+		if hash := sourceObjectContentHash(content); hash != d.Get("content_hash").(string) {
+			if err = d.SetNew("content_hash", hash); err != nil {
+				return err
+			}
+		}
+	} else if err := d.SetNewComputed("content_hash"); err != nil {
+		return err
+	}
+
+	uploading := d.Id() == "" || d.HasChange("content_hash") || d.HasChange("content_type")
+	if !uploading || !d.NewValueKnown("source_id") {
+		return nil
+	}
+
+	return checkSourceAllowsUpload(ctx, i.(*client), d.Get("source_id").(string))
+}
+
+func checkSourceAllowsUpload(ctx context.Context, c *client, sourceId string) error {
+	source, err := c.getSourceById(ctx, sourceId)
+	if err != nil {
+		return fmt.Errorf("Error reading source %s: %s", sourceId, err.Error())
+	}
+
+	if source == nil || source.Id == nil {
+		return fmt.Errorf("Source %s not found", sourceId)
+	}
+
+	if allows := source.Attributes.Deployment.AllowsUpload; allows == nil || !*allows {
+		return fmt.Errorf(
+			"Source %s doesn't allow uploads, imgix needs write permissions to its %s origin",
+			sourceId,
+			source.Attributes.Deployment.Type,
+		)
+	}
+	return nil
+}
+
+func resourceSourceObjectRead(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	originPath := d.Get("origin_path").(string)
+
+	// the API doesn't expose content of origin files, only whether the asset of the file still exists
+	asset, err := c.getAsset(ctx, sourceId, originPath)
+	if err != nil {
+		return diag.Errorf("Error reading object %s: %s", d.Id(), err.Error())
+	}
+
+	if asset != nil {
+		d.Set("asset_id", asset.Id)
+		return nil
+	}
+
+	// assets are indexed some time after the upload, so only objects whose asset was seen before are gone
+	if d.Get("asset_id").(string) == "" {
+		log.Printf("[DEBUG] Object %s isn't indexed yet, keeping it in state", d.Id())
+		return nil
+	}
+
+	log.Printf("[WARN] Object %s not found, removing from state", d.Id())
+	d.SetId("")
+	return nil
+}
+
+func resourceSourceObjectPut(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	originPath := d.Get("origin_path").(string)
+
+	content, err := readSourceObjectContent(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = checkSourceAllowsUpload(ctx, c, sourceId); err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err = c.uploadObject(ctx, sourceId, originPath, d.Get("content_type").(string), content); err != nil {
+		return diag.Errorf("Error uploading %s to source %s: %s", originPath, sourceId, err.Error())
+	}
+
+	d.SetId(sourceId + originPath)
+	d.Set("content_hash", sourceObjectContentHash(content))
+
+	return resourceSourceObjectRead(ctx, d, i)
+}
+
+func resourceSourceObjectDelete(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	return diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  "Object was removed from state only",
+			Detail:   "Imgix API can't delete files from the origin, remove them from the storage directly",
+		},
+	}
+}
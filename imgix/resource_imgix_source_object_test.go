@@ -0,0 +1,200 @@
+package imgix
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestReadingSourceObjectContent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "placeholder.png")
+	if err := ioutil.WriteFile(path, []byte("from file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		raw      map[string]interface{}
+		expected string
+	}{
+		"file":   {map[string]interface{}{"source": path}, "from file"},
+		"base64": {map[string]interface{}{"content_base64": base64.StdEncoding.EncodeToString([]byte("inline"))}, "inline"},
+	}
+
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceImgixSourceObject().Schema, c.raw)
+			content, err := readSourceObjectContent(d)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != c.expected {
+				t.Errorf("content should be %s, got %s", c.expected, content)
+			}
+		})
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceImgixSourceObject().Schema, map[string]interface{}{
+		"source": filepath.Join(t.TempDir(), "missing.png"),
+	})
+	if _, err := readSourceObjectContent(d); err == nil {
+		t.Error("reading missing file should fail")
+	}
+}
+
+func TestUploadingObject(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	sourceId := seedUploadSource(api, true)
+
+	if err := c.uploadObject(context.Background(), sourceId, "/errors/missing.png", "image/png", []byte("png")); err != nil {
+		t.Fatalf("upload error should be nil: %s", err)
+	}
+
+	if o, ok := api.Object(sourceId, "/errors/missing.png"); !ok || string(o.Content) != "png" || o.ContentType != "image/png" {
+		t.Errorf("uploaded object doesnt match expected: %+v", o)
+	}
+
+	readOnlySourceId := seedUploadSource(api, false)
+	if err := c.uploadObject(context.Background(), readOnlySourceId, "/errors/missing.png", "image/png", []byte("png")); err == nil {
+		t.Error("uploading to source without upload permissions should fail")
+	}
+}
+
+func seedUploadSource(api *fakeimgix.Server, allowsUpload bool) string {
+	return api.SeedSource(map[string]interface{}{
+		"name": fmt.Sprintf("uploads-%t", allowsUpload),
+		"deployment": map[string]interface{}{
+			"allows_upload":    allowsUpload,
+			"type":             "s3",
+			"imgix_subdomains": []string{fmt.Sprintf("uploads-%t", allowsUpload)},
+		},
+	})
+}
+
+func TestAccImgixSourceObject_basic(t *testing.T) {
+	api := startFakeApi(t)
+	sourceId := seedUploadSource(api, true)
+	path := filepath.Join(t.TempDir(), "placeholder.png")
+	if err := ioutil.WriteFile(path, []byte("file v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceObjectConfig(sourceId, fmt.Sprintf("content_base64 = %q", base64.StdEncoding.EncodeToString([]byte("inline v1")))),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source_object.test", "id", sourceId+"/errors/missing.png"),
+					resource.TestCheckResourceAttr("imgix_source_object.test", "content_hash", sourceObjectContentHash([]byte("inline v1"))),
+					resource.TestCheckResourceAttrSet("imgix_source_object.test", "asset_id"),
+					testAccCheckImgixSourceObject(api, sourceId, "inline v1", 1),
+				),
+			},
+			{
+				Config: testAccImgixSourceObjectConfig(sourceId, fmt.Sprintf("source = %q", path)),
+				Check:  testAccCheckImgixSourceObject(api, sourceId, "file v1", 2),
+			},
+			{
+				PreConfig: func() {
+					if err := ioutil.WriteFile(path, []byte("file v2"), 0644); err != nil {
+						t.Fatal(err)
+					}
+				},
+				Config: testAccImgixSourceObjectConfig(sourceId, fmt.Sprintf("source = %q", path)),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source_object.test", "content_hash", sourceObjectContentHash([]byte("file v2"))),
+					testAccCheckImgixSourceObject(api, sourceId, "file v2", 3),
+				),
+			},
+			{
+				// objects whose asset was indexed and then deleted are uploaded again
+				PreConfig: func() {
+					api.RemoveObject(sourceId, "/errors/missing.png")
+				},
+				Config: testAccImgixSourceObjectConfig(sourceId, fmt.Sprintf("source = %q", path)),
+				Check:  testAccCheckImgixSourceObject(api, sourceId, "file v2", 4),
+			},
+		},
+	})
+}
+
+func TestAccImgixSourceObject_notIndexed(t *testing.T) {
+	api := startFakeApi(t, fakeimgix.WithoutUploadIndexing())
+	sourceId := seedUploadSource(api, true)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				// objects without an asset yet are kept in state instead of being uploaded again
+				Config: testAccImgixSourceObjectConfig(sourceId, `content_base64 = "cG5n"`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckNoResourceAttr("imgix_source_object.test", "asset_id"),
+					testAccCheckImgixSourceObject(api, sourceId, "png", 1),
+				),
+			},
+			{
+				Config:   testAccImgixSourceObjectConfig(sourceId, `content_base64 = "cG5n"`),
+				PlanOnly: true,
+			},
+		},
+	})
+}
+
+func TestAccImgixSourceObject_uploadsNotAllowed(t *testing.T) {
+	api := startFakeApi(t)
+	sourceId := seedUploadSource(api, false)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImgixSourceObjectConfig(sourceId, `content_base64 = "cG5n"`),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile("doesn't allow uploads"),
+			},
+		},
+	})
+}
+
+func testAccCheckImgixSourceObject(api *fakeimgix.Server, sourceId, content string, uploads int) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		o, ok := api.Object(sourceId, "/errors/missing.png")
+		if !ok {
+			return fmt.Errorf("object was not uploaded")
+		}
+
+		if string(o.Content) != content || o.ContentType != "image/png" {
+			return fmt.Errorf("uploaded object doesnt match expected: %s %s", o.ContentType, o.Content)
+		}
+
+		if count := api.Uploads(sourceId, "/errors/missing.png"); count != uploads {
+			return fmt.Errorf("object should be uploaded %d times, got %d", uploads, count)
+		}
+		return nil
+	}
+}
+
+func testAccImgixSourceObjectConfig(sourceId, content string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_source_object" "test" {
+  source_id    = %[2]q
+  origin_path  = "/errors/missing.png"
+  content_type = "image/png"
+  %[3]s
+}
+`, fakeimgix.DefaultApiKey, sourceId, content)
+}
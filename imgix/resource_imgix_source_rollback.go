@@ -0,0 +1,163 @@
+package imgix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"log"
+	"time"
+)
+
+func resourceImgixSourceRollback() *schema.Resource {
+	return &schema.Resource{
+		Description:   "Rolls an Imgix source back to the settings of one of its previous deployments",
+		ReadContext:   resourceSourceRollbackRead,
+		CreateContext: resourceSourceRollbackCreate,
+		DeleteContext: resourceSourceRollbackDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(time.Minute * 30),
+		},
+		Schema: map[string]*schema.Schema{
+			"id": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceRollbackDescriptions["id"],
+			},
+			"source_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: sourceRollbackDescriptions["source_id"],
+			},
+			"deployment_id": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: sourceRollbackDescriptions["deployment_id"],
+			},
+			"annotation": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: sourceRollbackDescriptions["annotation"],
+			},
+			"wait_for_deployed": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				ForceNew:    true,
+				Default:     true,
+				Description: sourceRollbackDescriptions["wait_for_deployed"],
+			},
+			"deployment_status": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: sourceDescriptions["deployment_status"],
+			},
+			"deployment": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: sourceRollbackDescriptions["deployment"],
+				Elem:        dataSourceSourceDeploymentSchema(),
+			},
+		},
+	}
+}
+
+func resourceSourceRollbackRead(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	// a rollback is a one-off action, the source itself is read by imgix_source
+	return nil
+}
+
+func resourceSourceRollbackCreate(ctx context.Context, d *schema.ResourceData, i interface{}) diag.Diagnostics {
+	c := i.(*client)
+	sourceId := d.Get("source_id").(string)
+	deploymentId := d.Get("deployment_id").(string)
+
+	target, err := findSourceDeployment(ctx, c, sourceId, deploymentId)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	annotation := d.Get("annotation").(string)
+	if annotation == "" {
+		annotation = fmt.Sprintf("Rollback to deployment %s", deploymentId)
+	}
+
+	log.Printf("[DEBUG] Rolling back source %s to deployment %s", sourceId, deploymentId)
+	err = modifySourceDeployment(ctx, c, sourceId, func(_ sourceDeployment) (map[string]interface{}, error) {
+		return rollbackDeploymentChanges(target.Attributes.Deployment, annotation)
+	})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", sourceId, deploymentId))
+
+	source, err := c.getSourceById(ctx, sourceId)
+	if err == nil && source == nil {
+		err = fmt.Errorf("Source %s not found", sourceId)
+	}
+	if err == nil && d.Get("wait_for_deployed").(bool) {
+		status := sourceStatus(source.Attributes.Enabled == nil || *source.Attributes.Enabled)
+		source, err = waitForSourceStatus(ctx, c, sourceId, status, d.Timeout(schema.TimeoutCreate))
+	}
+
+	if err != nil {
+		return diag.Errorf("Error reading source %s after rollback: %s", sourceId, err.Error())
+	}
+
+	d.Set("deployment_status", source.Attributes.DeploymentStatus)
+	d.Set("deployment", []interface{}{flattenSourceDeployment(source.Attributes.Deployment)})
+
+	return diag.Diagnostics{
+		{
+			Severity: diag.Warning,
+			Summary:  "Update the imgix_source configuration to match the rollback",
+			Detail: fmt.Sprintf(
+				"Source %s was rolled back to deployment %s. Unless its imgix_source configuration is changed to match, the next apply will deploy it again.",
+				sourceId,
+				deploymentId,
+			),
+		},
+	}
+}
+
+func resourceSourceRollbackDelete(_ context.Context, _ *schema.ResourceData, _ interface{}) diag.Diagnostics {
+	return nil
+}
+
+// rollbackDeploymentChanges returns the settings of a previous deployment to patch into the source.
+// Secrets are never returned by the API, so s3_secret_key is left out and imgix keeps the current one.
+// The read-only allows_upload is left out like in every other update.
+func rollbackDeploymentChanges(deployment sourceDeployment, annotation string) (map[string]interface{}, error) {
+	deployment.Annotation = annotation
+	deployment.AllowsUpload = nil
+	b, err := json.Marshal(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("Error marshalling deployment: %s", err.Error())
+	}
+
+	changes := map[string]interface{}{}
+	if err = json.Unmarshal(b, &changes); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling deployment: %s", err.Error())
+	}
+
+	delete(changes, "s3_secret_key")
+	return changes, nil
+}
+
+func findSourceDeployment(ctx context.Context, c *client, sourceId, deploymentId string) (*SourceDeploymentHistory, error) {
+	deployments, err := c.listSourceDeployments(ctx, sourceId)
+	if err != nil {
+		return nil, fmt.Errorf("Error listing deployments of source %s: %s", sourceId, err.Error())
+	}
+
+	for _, deployment := range deployments {
+		if deployment.Id != nil && *deployment.Id == deploymentId {
+			return deployment, nil
+		}
+	}
+	return nil, fmt.Errorf("Deployment %s of source %s not found", deploymentId, sourceId)
+}
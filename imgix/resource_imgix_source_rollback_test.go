@@ -0,0 +1,170 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"regexp"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+)
+
+func TestAccImgixSourceRollback_basic(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+			"default_params":   map[string]interface{}{"auto": "format"},
+		},
+	})
+
+	source, err := c.getSourceById(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source.Attributes.Deployment.Annotation = "broken params"
+	source.Attributes.Deployment.DefaultParams = map[string]interface{}{"w": "0"}
+	if _, err = c.updateSource(context.Background(), source); err != nil {
+		t.Fatal(err)
+	}
+
+	deployments, err := c.listSourceDeployments(context.Background(), id)
+	if err != nil || len(deployments) != 2 {
+		t.Fatalf("expected 2 deployments, got %d: %v", len(deployments), err)
+	}
+	initialId := *deployments[1].Id
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImgixSourceRollbackConfig(id, "missing"),
+				ExpectError: regexp.MustCompile("Deployment missing of source .* not found"),
+			},
+			{
+				Config: testAccImgixSourceRollbackConfig(id, initialId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source_rollback.test", "id", id+"/"+initialId),
+					resource.TestCheckResourceAttr("imgix_source_rollback.test", "deployment_status", "deployed"),
+					resource.TestCheckResourceAttr("imgix_source_rollback.test", "deployment.0.default_params.auto", "format"),
+					resource.TestCheckResourceAttr("imgix_source_rollback.test", "deployment.0.annotation", "Rollback to deployment "+initialId),
+					testAccCheckImgixSourceDefaultParams(api, id, map[string]interface{}{"auto": "format"}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccImgixSourceRollback_disabled(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+			"default_params":   map[string]interface{}{"auto": "format"},
+		},
+	})
+
+	if err := c.patchSourceDeployment(context.Background(), id, map[string]interface{}{"default_params": map[string]interface{}{"w": "0"}}); err != nil {
+		t.Fatal(err)
+	}
+	deployments, err := c.listSourceDeployments(context.Background(), id)
+	if err != nil || len(deployments) != 2 {
+		t.Fatalf("expected 2 deployments, got %d: %v", len(deployments), err)
+	}
+	initialId := *deployments[1].Id
+
+	api.UpdateSource(id, func(attributes map[string]interface{}) {
+		attributes["enabled"] = false
+		attributes["deployment_status"] = "disabled"
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceRollbackConfig(id, initialId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source_rollback.test", "deployment_status", "disabled"),
+					testAccCheckImgixSourceDefaultParams(api, id, map[string]interface{}{"auto": "format"}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccImgixSourceRollback_s3SecretKey(t *testing.T) {
+	c, api := prepareFakeApiTest(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "s3",
+			"imgix_subdomains": []string{"example-1"},
+			"s3_bucket":        "bucket",
+			"s3_access_key":    "access",
+			"s3_secret_key":    "secret",
+		},
+	})
+
+	if err := c.patchSourceDeployment(context.Background(), id, map[string]interface{}{"s3_prefix": "broken"}); err != nil {
+		t.Fatal(err)
+	}
+	deployments, err := c.listSourceDeployments(context.Background(), id)
+	if err != nil || len(deployments) != 2 {
+		t.Fatalf("expected 2 deployments, got %d: %v", len(deployments), err)
+	}
+	initialId := *deployments[1].Id
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceRollbackConfig(id, initialId),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source_rollback.test", "deployment.0.s3_prefix", ""),
+					func(_ *terraform.State) error {
+						if secret := api.S3SecretKey(id); secret != "secret" {
+							return fmt.Errorf("source %s S3 secret key should be kept, got %q", id, secret)
+						}
+						if latest := api.Deployments(id)[0]; latest["annotation"] != "Rollback to deployment "+initialId {
+							return fmt.Errorf("source %s should be deployed by the rollback, got %v", id, latest)
+						}
+						return nil
+					},
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckImgixSourceDefaultParams(api *fakeimgix.Server, id string, expected map[string]interface{}) resource.TestCheckFunc {
+	return func(_ *terraform.State) error {
+		attributes, ok := api.Source(id)
+		if !ok {
+			return fmt.Errorf("source %s not found", id)
+		}
+
+		params := attributes["deployment"].(map[string]interface{})["default_params"]
+		if fmt.Sprint(params) != fmt.Sprint(expected) {
+			return fmt.Errorf("source %s default_params should be %v, got %v", id, expected, params)
+		}
+		return nil
+	}
+}
+
+func testAccImgixSourceRollbackConfig(sourceId, deploymentId string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %q
+}
+
+resource "imgix_source_rollback" "test" {
+  source_id     = %q
+  deployment_id = %q
+}
+`, fakeimgix.DefaultApiKey, sourceId, deploymentId)
+}
@@ -0,0 +1,450 @@
+package imgix
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"net/http"
+	"regexp"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+	"time"
+)
+
+func TestResolvingSourceImportId(t *testing.T) {
+	c := prepareHttpTest(t)
+	cases := map[string]struct {
+		id    string
+		valid bool
+	}{
+		testSourceId:             {testSourceId, true},
+		"name:source2":           {"601430223753592c4e822e2d", true},
+		"subdomain:example-2":    {testSourceId, true},
+		"subdomain:example-3":    {"601430223753592c4e822e2d", true},
+		"name:source3":           {"", false},
+		"subdomain:example":      {"", false},
+		"subdomain:example-1.ix": {"", false},
+	}
+
+	for importId, expected := range cases {
+		t.Run(importId, func(t *testing.T) {
+			id, err := resolveSourceImportId(context.Background(), c, importId)
+			if err == nil && !expected.valid {
+				t.Errorf("Import id %s should not resolve", importId)
+			} else if err != nil && expected.valid {
+				t.Errorf("Import id %s should resolve: %s", importId, err)
+			} else if id != expected.id {
+				t.Errorf("Import id %s resolved to %s instead of %s", importId, id, expected.id)
+			}
+		})
+	}
+}
+
+func TestImportingSource(t *testing.T) {
+	c := prepareHttpTest(t)
+	t.Setenv(importS3SecretKeyEnv, "secret")
+
+	d := schema.TestResourceDataRaw(t, resourceImgixSource().Schema, map[string]interface{}{})
+	d.SetId("subdomain:example-1")
+
+	res, err := resourceSourceImport(context.Background(), d, c)
+	if err != nil {
+		t.Fatalf("import error should be nil: %s", err)
+	}
+
+	d = res[0]
+	expected := map[string]interface{}{
+		"id":                                   testSourceId,
+		"name":                                 "source1",
+		"wait_for_deployed":                    true,
+		"deployment.0.type":                    "s3",
+		"deployment.0.annotation":              "source1 annotation",
+		"deployment.0.cache_ttl_value":         31536000,
+		"deployment.0.imgix_subdomains.1":      "example-2",
+		"deployment.0.s3_access_key":           "AKIABCDEFGHI",
+		"deployment.0.s3_secret_key":           "secret",
+		"deployment.0.s3_bucket":               "abc-bucket",
+		"deployment.0.secure_url_enabled":      false,
+		"deployment.0.image_missing_append_qs": false,
+	}
+
+	for k, v := range expected {
+		if actual := d.Get(k); actual != v {
+			t.Errorf("%s should be %v, got %v", k, v, actual)
+		}
+	}
+}
+
+func TestFlatteningSourceDeploymentDefaultParams(t *testing.T) {
+	deployment := flattenSourceDeployment(sourceDeployment{
+		DefaultParams: map[string]interface{}{
+			"auto": "format",
+			"q":    float64(75),
+		},
+	})
+
+	params := deployment["default_params"].(map[string]interface{})
+	if params["auto"] != "format" || params["q"] != "75" {
+		t.Errorf("default params were not flattened to strings: %v", params)
+	}
+}
+
+func TestWaitingForSourceStatusIsCancelled(t *testing.T) {
+	c, api := prepareFakeApiTest(t, fakeimgix.WithDeployDelay(time.Hour))
+	id := api.SeedSource(map[string]interface{}{
+		"name": "cancelled",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"cancelled"},
+		},
+	})
+	if err := c.disableSource(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := waitForSourceStatus(ctx, c, id, "disabled", time.Hour); err == nil {
+		t.Fatal("waiting for a source which is still disabling should fail when the context is cancelled")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("waiting should stop when the context is cancelled, took %s", elapsed)
+	}
+}
+
+func startFakeApi(t *testing.T, options ...fakeimgix.Option) *fakeimgix.Server {
+	api := fakeimgix.New(options...)
+	t.Cleanup(api.Close)
+	return api
+}
+
+func TestAccImgixSource_basic(t *testing.T) {
+	api := startFakeApi(t, fakeimgix.WithDeployDelay(2*time.Second))
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		CheckDestroy:      testAccCheckImgixSourceDisabled(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceConfig("basic-test", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment_status", "deployed"),
+					resource.TestCheckResourceAttr("imgix_source.test", "enabled", "true"),
+					resource.TestCheckResourceAttrSet("imgix_source.test", "date_deployed"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.cache_ttl_behavior", "respect_origin"),
+					testAccCheckImgixSourceSecretKey(api, "secret"),
+				),
+			},
+			{
+				Config: testAccImgixSourceConfig("basic-test", `
+    annotation     = "updated"
+    default_params = {
+      auto = "format"
+    }
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment_status", "deployed"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.annotation", "updated"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.default_params.auto", "format"),
+					testAccCheckImgixSourceAttributes(api, func(attributes map[string]interface{}) error {
+						deployment := attributes["deployment"].(map[string]interface{})
+						if deployment["annotation"] != "updated" {
+							return fmt.Errorf("annotation was not updated: %v", deployment["annotation"])
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
+func TestAccImgixSource_import(t *testing.T) {
+	api := startFakeApi(t)
+	t.Setenv(importS3SecretKeyEnv, "secret")
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceConfig("import-test", ""),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment_status", "deployed"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.s3_secret_key", "secret"),
+				),
+			},
+			{
+				ResourceName:      "imgix_source.test",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "imgix_source.test",
+				ImportState:       true,
+				ImportStateId:     "name:import-test",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:      "imgix_source.test",
+				ImportState:       true,
+				ImportStateId:     "subdomain:import-test",
+				ImportStateVerify: true,
+			},
+			{
+				ResourceName:  "imgix_source.test",
+				ImportState:   true,
+				ImportStateId: "name:missing",
+				ExpectError:   regexp.MustCompile("No source found for import name:missing"),
+			},
+		},
+	})
+}
+
+func TestAccImgixSource_s3Compatible(t *testing.T) {
+	api := startFakeApi(t)
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImgixSourceS3CompatibleConfig("minio-test", ""),
+				ExpectError: regexp.MustCompile("deployment.s3_endpoint is required for s3_compatible deployments"),
+			},
+			{
+				Config:      testAccImgixSourceConfig("minio-test", `s3_region = "us-east-1"`),
+				ExpectError: regexp.MustCompile("deployment.s3_region is only supported for s3_compatible deployments"),
+			},
+			{
+				Config: testAccImgixSourceS3CompatibleConfig("minio-test", `
+    s3_endpoint   = "https://minio.example.com:9000"
+    s3_region     = "us-east-1"
+    s3_path_style = true
+`),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.type", "s3_compatible"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.s3_endpoint", "https://minio.example.com:9000"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.s3_region", "us-east-1"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.s3_path_style", "true"),
+					testAccCheckImgixSourceAttributes(api, func(attributes map[string]interface{}) error {
+						deployment := attributes["deployment"].(map[string]interface{})
+						if deployment["s3_endpoint"] != "https://minio.example.com:9000" || deployment["s3_path_style"] != true {
+							return fmt.Errorf("S3-compatible storage was not sent: %v", deployment)
+						}
+						return nil
+					}),
+				),
+			},
+			{
+				ResourceName:            "imgix_source.test",
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"deployment.0.s3_secret_key"},
+			},
+		},
+	})
+}
+
+func TestAccImgixSource_createDisabled(t *testing.T) {
+	api := startFakeApi(t, fakeimgix.WithDeployDelay(2*time.Second))
+	var id string
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		CheckDestroy:      testAccCheckImgixSourceDisabled(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceEnabledConfig(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "enabled", "false"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment_status", "disabled"),
+					testAccCheckImgixSourceAttributes(api, func(attributes map[string]interface{}) error {
+						if attributes["enabled"] != false {
+							return fmt.Errorf("source was not disabled")
+						}
+						return nil
+					}),
+					func(s *terraform.State) error {
+						id = s.RootModule().Resources["imgix_source.test"].Primary.ID
+						return nil
+					},
+				),
+			},
+			{
+				// re-enabling the source outside of Terraform shows as drift instead of waiting for it to be disabled
+				PreConfig: func() {
+					api.UpdateSource(id, func(attributes map[string]interface{}) {
+						attributes["enabled"] = true
+						attributes["deployment_status"] = "deployed"
+					})
+				},
+				Config:             testAccImgixSourceEnabledConfig(false),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: testAccImgixSourceEnabledConfig(true),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "enabled", "true"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment_status", "deployed"),
+				),
+			},
+			{
+				Config: testAccImgixSourceEnabledConfig(false),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment_status", "disabled"),
+				),
+			},
+		},
+	})
+}
+
+func TestAccImgixSource_validationError(t *testing.T) {
+	api := startFakeApi(t)
+	api.SeedSource(map[string]interface{}{
+		"name": "existing",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"taken-test"},
+		},
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImgixSourceConfig("taken-test", ""),
+				ExpectError: regexp.MustCompile("Subdomain taken-test is already used"),
+			},
+		},
+	})
+}
+
+func TestAccImgixSource_serverError(t *testing.T) {
+	api := startFakeApi(t)
+	api.InjectFault(fakeimgix.Fault{
+		Method: http.MethodPost,
+		Path:   testSourcesEndpoint,
+		Status: http.StatusServiceUnavailable,
+	})
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccImgixSourceConfig("error-test", ""),
+				ExpectError: regexp.MustCompile("status: 503"),
+			},
+		},
+	})
+}
+
+func testAccCheckImgixSourceAttributes(api *fakeimgix.Server, check func(attributes map[string]interface{}) error) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		rs, ok := s.RootModule().Resources["imgix_source.test"]
+		if !ok {
+			return fmt.Errorf("imgix_source.test not found in state")
+		}
+
+		attributes, ok := api.Source(rs.Primary.ID)
+		if !ok {
+			return fmt.Errorf("source %s not found", rs.Primary.ID)
+		}
+		return check(attributes)
+	}
+}
+
+func testAccCheckImgixSourceSecretKey(api *fakeimgix.Server, expected string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources["imgix_source.test"].Primary.ID
+		if secret := api.S3SecretKey(id); secret != expected {
+			return fmt.Errorf("source %s has S3 secret key %q instead of %q", id, secret, expected)
+		}
+		return nil
+	}
+}
+
+func testAccCheckImgixSourceDisabled(api *fakeimgix.Server) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		for _, rs := range s.RootModule().Resources {
+			if rs.Type != "imgix_source" {
+				continue
+			}
+
+			attributes, ok := api.Source(rs.Primary.ID)
+			if ok && attributes["enabled"] != false {
+				return fmt.Errorf("source %s is still enabled", rs.Primary.ID)
+			}
+			if ok && attributes["deployment_status"] != "disabled" {
+				return fmt.Errorf("source %s is still %v after destroy", rs.Primary.ID, attributes["deployment_status"])
+			}
+		}
+		return nil
+	}
+}
+
+func testAccImgixSourceConfig(name, extraDeployment string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_source" "test" {
+  name = %[2]q
+
+  deployment {
+    type             = "s3"
+    imgix_subdomains = [%[2]q]
+
+    s3_access_key = "AKIABCDEFGHI"
+    s3_secret_key = "secret"
+    s3_bucket     = "abc-bucket"
+    s3_prefix     = "imgix-files"
+%[3]s
+  }
+}
+`, fakeimgix.DefaultApiKey, name, extraDeployment)
+}
+
+func testAccImgixSourceS3CompatibleConfig(name, extraDeployment string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_source" "test" {
+  name = %[2]q
+
+  deployment {
+    type             = "s3_compatible"
+    imgix_subdomains = [%[2]q]
+
+    s3_access_key = "minioadmin"
+    s3_secret_key = "secret"
+    s3_bucket     = "abc-bucket"
+%[3]s
+  }
+}
+`, fakeimgix.DefaultApiKey, name, extraDeployment)
+}
+
+func testAccImgixSourceEnabledConfig(enabled bool) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+}
+
+resource "imgix_source" "test" {
+  name    = "staged-test"
+  enabled = %[2]t
+
+  deployment {
+    type             = "webfolder"
+    imgix_subdomains = ["staged-test"]
+  }
+}
+`, fakeimgix.DefaultApiKey, enabled)
+}
@@ -0,0 +1,303 @@
+package imgix
+
+import (
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"reflect"
+)
+
+// Defaults of the imgix_source deployment attributes, sent when neither the deployment nor the provider sets them
+const (
+	defaultCacheTtlBehavior = "respect_origin"
+	defaultCacheTtlError    = 300
+	defaultCacheTtlValue    = 31536000
+)
+
+// sourceDefaultsKeys are the deployment attributes which can be set in the provider source_defaults block
+var sourceDefaultsKeys = []string{
+	"cache_ttl_behavior",
+	"cache_ttl_error",
+	"cache_ttl_value",
+	"default_params",
+	"image_error",
+	"secure_url_enabled",
+}
+
+// unsetDeploymentValues are the values of source_defaults attributes which neither the deployment nor the provider sets
+var unsetDeploymentValues = map[string]interface{}{
+	"cache_ttl_behavior": defaultCacheTtlBehavior,
+	"cache_ttl_error":    defaultCacheTtlError,
+	"cache_ttl_value":    defaultCacheTtlValue,
+	"image_error":        "",
+	"secure_url_enabled": false,
+}
+
+// sourceDefaults are deployment values of the provider source_defaults block, only set values are present
+type sourceDefaults struct {
+	values        map[string]interface{}
+	defaultParams map[string]interface{}
+}
+
+func sourceDefaultsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Deployment settings merged into every imgix_source. Values set in the imgix_source deployment take precedence",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"cache_ttl_behavior": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: sourceDescriptions["cache_ttl_behavior"],
+					ValidateFunc: validation.StringInSlice([]string{
+						"respect_origin",
+						"override_origin",
+						"enforce_minimum",
+					}, false),
+				},
+				"cache_ttl_error": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Description:  sourceDescriptions["cache_ttl_error"],
+					ValidateFunc: validation.IntBetween(1, 31536000),
+				},
+				"cache_ttl_value": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Description:  sourceDescriptions["cache_ttl_value"],
+					ValidateFunc: validation.IntBetween(1, 31536000),
+				},
+				"default_params": {
+					Type:        schema.TypeMap,
+					Optional:    true,
+					Description: sourceDescriptions["default_params"],
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				"image_error": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: sourceDescriptions["image_error"],
+				},
+				"secure_url_enabled": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Description: sourceDescriptions["secure_url_enabled"],
+				},
+			},
+		},
+	}
+}
+
+// effectiveDeploymentSchema is the computed imgix_source attribute showing the deployment values after
+// source defaults are merged, similar to tags_all of the AWS provider
+func effectiveDeploymentSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: sourceDescriptions["effective_deployment"],
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"cache_ttl_behavior": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: sourceDescriptions["cache_ttl_behavior"],
+				},
+				"cache_ttl_error": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: sourceDescriptions["cache_ttl_error"],
+				},
+				"cache_ttl_value": {
+					Type:        schema.TypeInt,
+					Computed:    true,
+					Description: sourceDescriptions["cache_ttl_value"],
+				},
+				"default_params": {
+					Type:        schema.TypeMap,
+					Computed:    true,
+					Description: sourceDescriptions["default_params"],
+					Elem:        &schema.Schema{Type: schema.TypeString},
+				},
+				"image_error": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: sourceDescriptions["image_error"],
+				},
+				"secure_url_enabled": {
+					Type:        schema.TypeBool,
+					Computed:    true,
+					Description: sourceDescriptions["secure_url_enabled"],
+				},
+			},
+		},
+	}
+}
+
+// sourceDefaultsFromResourceData returns nil when the provider has no source_defaults block
+func sourceDefaultsFromResourceData(d *schema.ResourceData) *sourceDefaults {
+	blocks := d.Get("source_defaults").([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return nil
+	}
+
+	block := blocks[0].(map[string]interface{})
+	defaults := &sourceDefaults{
+		values:        map[string]interface{}{},
+		defaultParams: block["default_params"].(map[string]interface{}),
+	}
+
+	for key, unset := range unsetDeploymentValues {
+		// an empty value in the block is left out, so the built-in default of the attribute is used
+		if v := block[key]; !reflect.DeepEqual(v, reflect.Zero(reflect.TypeOf(unset)).Interface()) {
+			defaults.values[key] = v
+		}
+	}
+	return defaults
+}
+
+// configuredDeploymentKeys returns the source_defaults attributes set in the deployment block of an imgix_source
+// configuration. It returns nil when there is no configuration, e.g. on delete, as all values in state are kept then.
+func configuredDeploymentKeys(config cty.Value) map[string]bool {
+	if config.IsNull() || !config.IsKnown() {
+		return nil
+	}
+
+	configured := map[string]bool{}
+	deployments := config.GetAttr("deployment")
+	if deployments.IsNull() || !deployments.IsKnown() || deployments.LengthInt() == 0 {
+		return configured
+	}
+
+	deployment := deployments.Index(cty.NumberIntVal(0))
+	for key := range unsetDeploymentValues {
+		configured[key] = !deployment.GetAttr(key).IsNull()
+	}
+	return configured
+}
+
+// merge returns a copy of a deployment of the imgix_source schema with the defaults applied.
+// Configured values win, a nil configured keeps every value of the deployment.
+func (s *sourceDefaults) merge(deployment map[string]interface{}, configured map[string]bool) map[string]interface{} {
+	merged := make(map[string]interface{}, len(deployment))
+	for k, v := range deployment {
+		merged[k] = v
+	}
+
+	if configured != nil {
+		for key, unset := range unsetDeploymentValues {
+			if configured[key] {
+				continue
+			}
+
+			merged[key] = unset
+			if v, ok := s.value(key); ok {
+				merged[key] = v
+			}
+		}
+	}
+
+	if s == nil {
+		return merged
+	}
+
+	params := make(map[string]interface{}, len(s.defaultParams))
+	for k, v := range s.defaultParams {
+		params[k] = v
+	}
+	if own, ok := deployment["default_params"].(map[string]interface{}); ok {
+		for k, v := range own {
+			params[k] = v
+		}
+	}
+	merged["default_params"] = params
+
+	return merged
+}
+
+func (s *sourceDefaults) value(key string) (interface{}, bool) {
+	if s == nil {
+		return nil, false
+	}
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// unmergeDefaultParams turns default params read from the API back into the params of the imgix_source
+// configuration, so state doesn't drift from the configuration because of the defaults. Params in prior
+// are kept, params coming from the current or the previously applied defaults are dropped, any other
+// param shows up as drift. Without prior, e.g. when importing, params equal to the defaults are dropped.
+func (s *sourceDefaults) unmergeDefaultParams(params, prior, priorEffective map[string]interface{}) map[string]interface{} {
+	var defaultParams map[string]interface{}
+	if s != nil {
+		defaultParams = s.defaultParams
+	}
+
+	unmerged := map[string]interface{}{}
+	for k, v := range params {
+		if prior == nil {
+			if d, ok := defaultParams[k]; ok && d == v {
+				continue
+			}
+		} else if _, own := prior[k]; !own {
+			_, fromDefaults := defaultParams[k]
+			_, fromPriorDefaults := priorEffective[k]
+			if fromDefaults || fromPriorDefaults {
+				continue
+			}
+		}
+		unmerged[k] = v
+	}
+	return unmerged
+}
+
+// effectiveDeployment picks the source_defaults attributes of a merged deployment
+func effectiveDeployment(merged map[string]interface{}) []interface{} {
+	effective := make(map[string]interface{}, len(sourceDefaultsKeys))
+	for _, key := range sourceDefaultsKeys {
+		effective[key] = merged[key]
+	}
+	return []interface{}{effective}
+}
+
+// normalizeFlattenedDeployment replaces pointers of a flattened API deployment with the values
+// stored in state, so it can be compared with deployments read from the configuration
+func normalizeFlattenedDeployment(deployment map[string]interface{}) map[string]interface{} {
+	if v, ok := deployment["image_error"].(*string); ok {
+		deployment["image_error"] = ""
+		if v != nil {
+			deployment["image_error"] = *v
+		}
+	}
+
+	if v, ok := deployment["secure_url_enabled"].(*bool); ok {
+		deployment["secure_url_enabled"] = v != nil && *v
+	}
+	return deployment
+}
+
+// customizeSourceDefaultsDiff plans the effective deployment of an imgix_source
+func customizeSourceDefaultsDiff(d *schema.ResourceDiff, defaults *sourceDefaults) error {
+	deployments := d.Get("deployment").([]interface{})
+	if len(deployments) != 1 || deployments[0] == nil {
+		return nil
+	}
+
+	configured := configuredDeploymentKeys(d.GetRawConfig())
+	for _, key := range sourceDefaultsKeys {
+		if (key == "default_params" || configured[key]) && !d.NewValueKnown("deployment.0."+key) {
+			return d.SetNewComputed("effective_deployment")
+		}
+	}
+
+	merged := defaults.merge(deployments[0].(map[string]interface{}), configured)
+	if d.Get("ignore_default_params").(bool) {
+		// default params are managed by imgix_source_default_param resources, they are only read
+		merged["default_params"] = map[string]interface{}{}
+		if effective := d.Get("effective_deployment").([]interface{}); len(effective) == 1 && effective[0] != nil {
+			merged["default_params"] = effective[0].(map[string]interface{})["default_params"]
+		}
+	}
+	return d.SetNew("effective_deployment", effectiveDeployment(merged))
+}
@@ -0,0 +1,201 @@
+package imgix
+
+import (
+	"fmt"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+	"reflect"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+	"time"
+)
+
+func testSourceDefaults() *sourceDefaults {
+	return &sourceDefaults{
+		values: map[string]interface{}{
+			"cache_ttl_value":    3600,
+			"secure_url_enabled": true,
+		},
+		defaultParams: map[string]interface{}{"auto": "format", "q": "75"},
+	}
+}
+
+func testUnsetDeployment() map[string]interface{} {
+	return map[string]interface{}{
+		"annotation":         "test",
+		"cache_ttl_behavior": defaultCacheTtlBehavior,
+		"cache_ttl_error":    defaultCacheTtlError,
+		"cache_ttl_value":    defaultCacheTtlValue,
+		"default_params":     map[string]interface{}{},
+		"image_error":        "",
+		"secure_url_enabled": false,
+	}
+}
+
+func TestMergingSourceDefaults(t *testing.T) {
+	deployment := testUnsetDeployment()
+	deployment["cache_ttl_error"] = 60
+	deployment["default_params"] = map[string]interface{}{"q": "90"}
+	configured := map[string]bool{"cache_ttl_error": true, "secure_url_enabled": true}
+
+	merged := testSourceDefaults().merge(deployment, configured)
+	expected := map[string]interface{}{
+		"annotation":         "test",
+		"cache_ttl_behavior": defaultCacheTtlBehavior,
+		"cache_ttl_error":    60,
+		"cache_ttl_value":    3600,
+		"default_params":     map[string]interface{}{"auto": "format", "q": "90"},
+		"image_error":        "",
+		"secure_url_enabled": false,
+	}
+	if !reflect.DeepEqual(merged, expected) {
+		t.Errorf("merged deployment should be %v, got %v", expected, merged)
+	}
+
+	if deployment["cache_ttl_value"] != defaultCacheTtlValue {
+		t.Error("merging should not change the deployment")
+	}
+
+	computed := testUnsetDeployment()
+	computed["cache_ttl_value"] = 3600
+	var none *sourceDefaults
+	if unset := none.merge(computed, map[string]bool{}); unset["cache_ttl_value"] != defaultCacheTtlValue {
+		t.Errorf("unset values should be reset to their default without defaults, got %v", unset["cache_ttl_value"])
+	}
+
+	if unchanged := testSourceDefaults().merge(computed, nil); unchanged["cache_ttl_value"] != 3600 || unchanged["secure_url_enabled"] != false {
+		t.Errorf("values should be kept without a configuration, got %v", unchanged)
+	}
+}
+
+func TestConfiguredDeploymentKeys(t *testing.T) {
+	attributes := map[string]cty.Value{}
+	for key := range unsetDeploymentValues {
+		attributes[key] = cty.NullVal(cty.String)
+	}
+	attributes["cache_ttl_behavior"] = cty.StringVal(defaultCacheTtlBehavior)
+	attributes["secure_url_enabled"] = cty.False
+	config := cty.ObjectVal(map[string]cty.Value{
+		"deployment": cty.ListVal([]cty.Value{cty.ObjectVal(attributes)}),
+	})
+
+	expected := map[string]bool{
+		"cache_ttl_behavior": true,
+		"cache_ttl_error":    false,
+		"cache_ttl_value":    false,
+		"image_error":        false,
+		"secure_url_enabled": true,
+	}
+	if configured := configuredDeploymentKeys(config); !reflect.DeepEqual(configured, expected) {
+		t.Errorf("configured keys should be %v, got %v", expected, configured)
+	}
+
+	if configured := configuredDeploymentKeys(cty.NullVal(config.Type())); configured != nil {
+		t.Errorf("configured keys should be nil without a configuration, got %v", configured)
+	}
+}
+
+func TestUnmergingSourceDefaultParams(t *testing.T) {
+	defaults := testSourceDefaults()
+	prior := map[string]interface{}{"q": "90"}
+	priorEffective := map[string]interface{}{"auto": "compress", "dpr": "2", "q": "90"}
+
+	read := map[string]interface{}{"auto": "compress", "dpr": "2", "q": "90", "fit": "crop"}
+	expected := map[string]interface{}{"q": "90", "fit": "crop"}
+	if unmerged := defaults.unmergeDefaultParams(read, prior, priorEffective); !reflect.DeepEqual(unmerged, expected) {
+		t.Errorf("params coming from the defaults should be dropped and others kept as %v, got %v", expected, unmerged)
+	}
+
+	imported := defaults.unmergeDefaultParams(map[string]interface{}{"auto": "format", "q": "90"}, nil, nil)
+	if expected := map[string]interface{}{"q": "90"}; !reflect.DeepEqual(imported, expected) {
+		t.Errorf("imported params equal to the defaults should be dropped, got %v", imported)
+	}
+}
+
+func TestAccImgixSource_sourceDefaults(t *testing.T) {
+	api := startFakeApi(t, fakeimgix.WithDeployDelay(time.Second))
+
+	resource.Test(t, resource.TestCase{
+		ProviderFactories: testAccProviderFactories(api.URL),
+		CheckDestroy:      testAccCheckImgixSourceDisabled(api),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccImgixSourceDefaultsConfig("3600", "format"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.cache_ttl_error", "300"),
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.default_params.%", "1"),
+					resource.TestCheckResourceAttr("imgix_source.test", "effective_deployment.0.cache_ttl_value", "3600"),
+					resource.TestCheckResourceAttr("imgix_source.test", "effective_deployment.0.cache_ttl_error", "300"),
+					resource.TestCheckResourceAttr("imgix_source.test", "effective_deployment.0.cache_ttl_behavior", "respect_origin"),
+					resource.TestCheckResourceAttr("imgix_source.test", "effective_deployment.0.secure_url_enabled", "false"),
+					resource.TestCheckResourceAttr("imgix_source.test", "effective_deployment.0.default_params.auto", "format"),
+					resource.TestCheckResourceAttr("imgix_source.test", "effective_deployment.0.default_params.q", "90"),
+					testAccCheckImgixSourceEffectiveDeployment(api, 3600, map[string]interface{}{"auto": "format", "q": "90"}),
+				),
+			},
+			{
+				Config: testAccImgixSourceDefaultsConfig("7200", "compress"),
+				Check: resource.ComposeTestCheckFunc(
+					resource.TestCheckResourceAttr("imgix_source.test", "deployment.0.default_params.%", "1"),
+					resource.TestCheckResourceAttr("imgix_source.test", "effective_deployment.0.cache_ttl_value", "7200"),
+					resource.TestCheckResourceAttr("imgix_source.test", "effective_deployment.0.default_params.auto", "compress"),
+					testAccCheckImgixSourceEffectiveDeployment(api, 7200, map[string]interface{}{"auto": "compress", "q": "90"}),
+				),
+			},
+		},
+	})
+}
+
+func testAccCheckImgixSourceEffectiveDeployment(api *fakeimgix.Server, cacheTtlValue int, params map[string]interface{}) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		id := s.RootModule().Resources["imgix_source.test"].Primary.ID
+		attributes, ok := api.Source(id)
+		if !ok {
+			return fmt.Errorf("source %s not found", id)
+		}
+
+		deployment := attributes["deployment"].(map[string]interface{})
+		if fmt.Sprint(deployment["cache_ttl_value"]) != fmt.Sprint(cacheTtlValue) {
+			return fmt.Errorf("source %s cache_ttl_value should be %d, got %v", id, cacheTtlValue, deployment["cache_ttl_value"])
+		}
+		if deployment["cache_ttl_error"] != float64(300) || deployment["cache_ttl_behavior"] != "respect_origin" || deployment["secure_url_enabled"] != false {
+			return fmt.Errorf("source %s values set in the resource should win, got %v", id, deployment)
+		}
+		return testAccCheckImgixSourceDefaultParams(api, id, params)(s)
+	}
+}
+
+func testAccImgixSourceDefaultsConfig(cacheTtlValue, auto string) string {
+	return fmt.Sprintf(`
+provider "imgix" {
+  api_key = %[1]q
+
+  source_defaults {
+    cache_ttl_behavior = "override_origin"
+    cache_ttl_value    = %[2]s
+    cache_ttl_error    = 600
+    secure_url_enabled = true
+    default_params = {
+      auto = %[3]q
+      q    = "75"
+    }
+  }
+}
+
+resource "imgix_source" "test" {
+  name = "defaults-test"
+
+  deployment {
+    type               = "webfolder"
+    imgix_subdomains   = ["defaults-test"]
+    cache_ttl_behavior = "respect_origin"
+    cache_ttl_error    = 300
+    secure_url_enabled = false
+    default_params = {
+      q = "90"
+    }
+  }
+}
+`, fakeimgix.DefaultApiKey, cacheTtlValue, auto)
+}
@@ -0,0 +1,93 @@
+package imgix
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"os"
+	"strings"
+)
+
+const (
+	tracerName = "terraform-provider-imgix"
+
+	// traceParentEnv links provider spans to a trace started by the calling pipeline
+	traceParentEnv = "TRACEPARENT"
+)
+
+// otlpEndpointEnvs enable the OTLP exporter, the exporter reads the rest of OTEL_EXPORTER_OTLP_* itself
+var otlpEndpointEnvs = []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"}
+
+// SetupTracing exports spans over OTLP when an OTLP endpoint is configured in the environment.
+// Tracing stays a no-op otherwise. The returned function flushes spans and has to be called before exiting.
+func SetupTracing(ctx context.Context) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	enabled := false
+	for _, env := range otlpEndpointEnvs {
+		if os.Getenv(env) != "" {
+			enabled = true
+		}
+	}
+
+	if !enabled {
+		return noop, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(tracerName))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// startSpan starts a span of the provider tracer. Spans without a parent in ctx
+// are linked to the trace in the TRACEPARENT environment variable, when it is set.
+func startSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		if parent := os.Getenv(traceParentEnv); parent != "" {
+			carrier := propagation.MapCarrier{"traceparent": parent}
+			ctx = propagation.TraceContext{}.Extract(ctx, carrier)
+		}
+	}
+
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// endSpan records the error, if any, and ends the span
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// resourceIdFromPath returns the id of the resource an API path points to,
+// e.g. the source id of /api/v1/sources/<id> or /api/v1/sources/upload/<id>/image.jpg
+func resourceIdFromPath(path string) string {
+	path = strings.SplitN(path, "?", 2)[0]
+	segments := strings.Split(strings.TrimPrefix(path, "/api/v1/"), "/")
+	if len(segments) < 2 {
+		return ""
+	}
+
+	segments = segments[1:]
+	if len(segments) > 1 && (segments[0] == "upload" || segments[0] == "refresh") {
+		segments = segments[1:]
+	}
+	return segments[0]
+}
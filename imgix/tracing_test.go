@@ -0,0 +1,193 @@
+package imgix
+
+import (
+	"context"
+	"errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"net/http"
+	"os"
+	"terraform-provider-imgix/internal/fakeimgix"
+	"testing"
+	"time"
+)
+
+// recordSpans installs a tracer provider exporting spans to memory until the test ends
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+	return exporter
+}
+
+func spanAttribute(span tracetest.SpanStub, key string) attribute.Value {
+	for _, a := range span.Attributes {
+		if string(a.Key) == key {
+			return a.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func spanAttributeOk(span tracetest.SpanStub, key string) (attribute.Value, bool) {
+	v := spanAttribute(span, key)
+	return v, v.Type() != attribute.INVALID
+}
+
+func TestTracingApiRequests(t *testing.T) {
+	spans := recordSpans(t)
+	c, api := prepareFakeApiTest(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+		},
+	})
+	api.InjectFault(fakeimgix.Fault{Method: http.MethodGet, Path: "/api/v1/sources/" + id, Status: http.StatusServiceUnavailable, Times: 1})
+
+	if _, err := c.getSourceById(context.Background(), id); err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := spans.GetSpans()
+	if len(recorded) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(recorded))
+	}
+
+	span := recorded[0]
+	expected := map[string]interface{}{
+		"http.request.method":       "GET",
+		"url.path":                  "/api/v1/sources/" + id,
+		"imgix.resource_id":         id,
+		"http.response.status_code": int64(200),
+		"http.request.resend_count": int64(1),
+		"imgix.request_id":          "fake-request-2",
+	}
+	for key, value := range expected {
+		if actual := spanAttribute(span, key).AsInterface(); actual != value {
+			t.Errorf("span attribute %s should be %v, got %v", key, value, actual)
+		}
+	}
+}
+
+func TestTracingApiRequestsInParentSpan(t *testing.T) {
+	spans := recordSpans(t)
+	c, api := prepareFakeApiTest(t)
+	id := api.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+		},
+	})
+
+	ctx, parent := startSpan(context.Background(), "parent")
+	if _, err := c.getSourceById(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	recorded := spans.GetSpans()
+	if len(recorded) != 2 || recorded[0].Parent.SpanID() != recorded[1].SpanContext.SpanID() {
+		t.Error("request span should be a child of the span in the request context")
+	}
+}
+
+func TestApiRequestIsCancelled(t *testing.T) {
+	c, _ := prepareFakeApiTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := c.listSources(ctx, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("request should be cancelled, got %v", err)
+	}
+}
+
+func TestTracingAssetRefreshWaiter(t *testing.T) {
+	skipAssetRefreshDelay(t)
+	spans := recordSpans(t)
+	c, sourceId := seedTestAsset(t)
+
+	err := waitForAssetsToBeRefreshed(context.Background(), c, sourceId, []string{"/images/hero image.jpg"}, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorded := spans.GetSpans()
+	if len(recorded) != 2 {
+		t.Fatalf("expected request and waiter spans, got %d", len(recorded))
+	}
+
+	request, waiter := recorded[0], recorded[1]
+	if waiter.Name != "imgix.wait_for_assets_refreshed" || spanAttribute(waiter, "imgix.resource_id").AsString() != sourceId {
+		t.Errorf("unexpected waiter span %s: %v", waiter.Name, waiter.Attributes)
+	}
+
+	if _, ok := spanAttributeOk(waiter, "imgix.wait_duration_ms"); !ok {
+		t.Error("waiter span should have a wait duration")
+	}
+
+	if request.Parent.SpanID() != waiter.SpanContext.SpanID() {
+		t.Error("request span should be a child of the waiter span")
+	}
+}
+
+func TestTracingWithTraceParent(t *testing.T) {
+	spans := recordSpans(t)
+	t.Setenv(traceParentEnv, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	_, span := startSpan(context.Background(), "test")
+	span.End()
+
+	recorded := spans.GetSpans()
+	if len(recorded) != 1 || recorded[0].SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("span should continue the trace from %s", traceParentEnv)
+	}
+}
+
+func TestSetupTracingWithoutEndpoint(t *testing.T) {
+	for _, env := range otlpEndpointEnvs {
+		if os.Getenv(env) != "" {
+			t.Skipf("%s is set", env)
+		}
+	}
+
+	previous := otel.GetTracerProvider()
+	shutdown, err := SetupTracing(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if otel.GetTracerProvider() != previous {
+		t.Error("tracer provider should not change without an OTLP endpoint")
+	}
+
+	if err = shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResourceIdFromPath(t *testing.T) {
+	cases := map[string]string{
+		"/api/v1/sources":                      "",
+		"/api/v1/sources?filter[name]=a":       "",
+		"/api/v1/sources/abc":                  "abc",
+		"/api/v1/sources/abc/deployments":      "abc",
+		"/api/v1/sources/upload/abc/image.jpg": "abc",
+		"/api/v1/assets/abc/image.jpg":         "abc",
+		"/api/v1/assets/refresh/abc/image.jpg": "abc",
+		"/api/v1/assets/abc?page[limit]=10":    "abc",
+	}
+
+	for path, expected := range cases {
+		if id := resourceIdFromPath(path); id != expected {
+			t.Errorf("resource id of %s should be %q, got %q", path, expected, id)
+		}
+	}
+}
@@ -13,6 +13,14 @@ func StringNilIfEmpty(v interface{}) *string {
 	return &vp
 }
 
+// StringValue returns the string pointed to, or an empty string for nil
+func StringValue(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
 func Int(v interface{}) *int {
 	vp := v.(int)
 	return &vp
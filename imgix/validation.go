@@ -3,7 +3,9 @@ package imgix
 import (
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"regexp"
 	"strings"
+	"time"
 )
 
 func validateSubdomain(i interface{}, _ cty.Path) diag.Diagnostics {
@@ -14,3 +16,45 @@ func validateSubdomain(i interface{}, _ cty.Path) diag.Diagnostics {
 
 	return nil
 }
+
+var customDomainRegexp = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}$`)
+
+func validateCustomDomain(i interface{}, _ cty.Path) diag.Diagnostics {
+	domain := i.(string)
+	if !customDomainRegexp.MatchString(domain) {
+		return diag.Errorf("Custom domain has to be a lowercase domain name without scheme or path. Invalid domain: %s", domain)
+	}
+
+	if strings.HasSuffix(domain, ".imgix.net") {
+		return diag.Errorf("Custom domain can't be an imgix.net domain, use imgix_subdomains instead. Invalid domain: %s", domain)
+	}
+
+	return nil
+}
+
+func validateRenderingParam(i interface{}, _ cty.Path) diag.Diagnostics {
+	key := i.(string)
+	if !isRenderingParam(key) {
+		return diag.Errorf("Unknown imgix rendering parameter %s, see https://docs.imgix.com/apis/rendering for the supported ones", key)
+	}
+
+	return nil
+}
+
+func validateDate(i interface{}, _ cty.Path) diag.Diagnostics {
+	date := i.(string)
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		return diag.Errorf("Date has to be in YYYY-MM-DD format. Invalid date: %s", date)
+	}
+
+	return nil
+}
+
+func validateDuration(i interface{}, _ cty.Path) diag.Diagnostics {
+	duration := i.(string)
+	if d, err := time.ParseDuration(duration); err != nil || d <= 0 {
+		return diag.Errorf("Duration has to be positive, such as 30s or 5m. Invalid duration: %s", duration)
+	}
+
+	return nil
+}
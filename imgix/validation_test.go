@@ -21,3 +21,70 @@ func TestValidatingSubdomains(t *testing.T) {
 		})
 	}
 }
+
+func TestValidatingDates(t *testing.T) {
+	cases := map[string]bool{
+		"2021-02-01":           true,
+		"2021-02-30":           false,
+		"2021-2-1":             false,
+		"2021-02-01T00:00:00Z": false,
+	}
+
+	for c, valid := range cases {
+		t.Run(c, func(t *testing.T) {
+			res := validateDate(c, nil)
+			if res == nil && !valid {
+				t.Errorf("Date %s is invalid", c)
+			} else if res != nil && valid {
+				t.Errorf("Date %s is valid", c)
+			}
+		})
+	}
+}
+
+func TestValidatingCustomDomains(t *testing.T) {
+	cases := map[string]bool{
+		"images.example.com":         true,
+		"cdn-2.example.co.uk":        true,
+		"example":                    false,
+		"Images.example.com":         false,
+		"https://images.example.com": false,
+		"images.example.com/path":    false,
+		"example.imgix.net":          false,
+	}
+
+	for c, valid := range cases {
+		t.Run(c, func(t *testing.T) {
+			res := validateCustomDomain(c, nil)
+			if res == nil && !valid {
+				t.Errorf("Domain %s is invalid", c)
+			} else if res != nil && valid {
+				t.Errorf("Domain %s is valid", c)
+			}
+		})
+	}
+}
+
+func TestValidatingRenderingParams(t *testing.T) {
+	cases := map[string]bool{
+		"auto":    true,
+		"fit":     true,
+		"txt64":   true,
+		"mark-w":  true,
+		"unknown": false,
+		"auto64":  true,
+		"w64x":    false,
+		"s":       false,
+	}
+
+	for c, valid := range cases {
+		t.Run(c, func(t *testing.T) {
+			res := validateRenderingParam(c, nil)
+			if res == nil && !valid {
+				t.Errorf("Parameter %s is invalid", c)
+			} else if res != nil && valid {
+				t.Errorf("Parameter %s is valid", c)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Package fakedns implements a local DNS stub answering CNAME records, used by the provider's tests
+// of custom domain DNS checks to run without network access.
+package fakedns
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// DNS message constants of RFC 1035
+const (
+	headerLen = 12
+
+	flagResponse           = 1 << 15
+	opcodeMask             = 0xf << 11
+	flagAuthoritative      = 1 << 10
+	flagRecursionDesired   = 1 << 8
+	flagRecursionAvailable = 1 << 7
+	rcodeNameError         = 3
+
+	typeA     = 1
+	typeCNAME = 5
+	classINET = 1
+
+	// maxChainLen limits the CNAME records of an answer, keeping responses small and loops finite
+	maxChainLen = 8
+)
+
+// Server answers DNS queries over UDP on a local address
+type Server struct {
+	Addr string
+
+	mu      sync.Mutex
+	conn    net.PacketConn
+	cnames  map[string]string
+	queries map[string]int
+}
+
+// New starts a DNS stub. It has to be stopped with Close.
+func New() (*Server, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		Addr:    conn.LocalAddr().String(),
+		conn:    conn,
+		cnames:  map[string]string{},
+		queries: map[string]int{},
+	}
+	go s.serve()
+	return s, nil
+}
+
+func (s *Server) Close() {
+	s.conn.Close()
+}
+
+// SetCNAME makes name resolve to target, an empty target removes the record
+func (s *Server) SetCNAME(name, target string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if target == "" {
+		delete(s.cnames, fqdn(name))
+		return
+	}
+	s.cnames[fqdn(name)] = fqdn(target)
+}
+
+// Queries returns the number of queries for name
+func (s *Server) Queries(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.queries[fqdn(name)]
+}
+
+func (s *Server) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+
+		if res, err := s.answer(buf[:n]); err == nil {
+			_, _ = s.conn.WriteTo(res, addr)
+		}
+	}
+}
+
+// answer returns the CNAME record of the question for CNAME queries. Other query types get the whole CNAME chain like
+// from a recursive resolver, followed by an address of the last name for A queries. Other names don't exist.
+func (s *Server) answer(req []byte) ([]byte, error) {
+	if len(req) < headerLen || binary.BigEndian.Uint16(req[4:6]) == 0 {
+		return nil, errors.New("query without question")
+	}
+
+	name, end, err := parseName(req, headerLen)
+	if err != nil {
+		return nil, err
+	}
+	// type and class follow the name
+	end += 4
+	if end > len(req) {
+		return nil, errors.New("truncated question")
+	}
+	qtype := binary.BigEndian.Uint16(req[end-4 : end-2])
+
+	s.mu.Lock()
+	s.queries[name]++
+	var chain [][2]string
+	for owner := name; len(chain) < maxChainLen && (qtype != typeCNAME || len(chain) == 0); {
+		target, ok := s.cnames[owner]
+		if !ok {
+			break
+		}
+		chain = append(chain, [2]string{owner, target})
+		owner = target
+	}
+	s.mu.Unlock()
+
+	// response with the opcode and recursion desired bit of the query, authoritative and recursion available
+	flags := flagResponse | binary.BigEndian.Uint16(req[2:4])&(opcodeMask|flagRecursionDesired) | flagAuthoritative | flagRecursionAvailable
+	answers := len(chain)
+	if answers == 0 {
+		flags |= rcodeNameError
+	} else if qtype == typeA {
+		answers++
+	}
+
+	res := make([]byte, headerLen, 512)
+	copy(res[0:2], req[0:2])
+	binary.BigEndian.PutUint16(res[2:4], flags)
+	binary.BigEndian.PutUint16(res[4:6], 1)
+	binary.BigEndian.PutUint16(res[6:8], uint16(answers))
+	res = append(res, req[headerLen:end]...)
+
+	for i, record := range chain {
+		if i == 0 {
+			// the first record name points to the question name
+			res = append(res, 0xc0, headerLen)
+		} else {
+			res = append(res, encodeName(record[0])...)
+		}
+
+		rdata := encodeName(record[1])
+		res = binary.BigEndian.AppendUint16(res, typeCNAME)
+		res = binary.BigEndian.AppendUint16(res, classINET)
+		res = binary.BigEndian.AppendUint32(res, 60)
+		res = binary.BigEndian.AppendUint16(res, uint16(len(rdata)))
+		res = append(res, rdata...)
+	}
+
+	if len(chain) > 0 && qtype == typeA {
+		res = append(res, encodeName(chain[len(chain)-1][1])...)
+		res = binary.BigEndian.AppendUint16(res, typeA)
+		res = binary.BigEndian.AppendUint16(res, classINET)
+		res = binary.BigEndian.AppendUint32(res, 60)
+		res = binary.BigEndian.AppendUint16(res, 4)
+		// documentation address of RFC 5737
+		res = append(res, 192, 0, 2, 1)
+	}
+	return res, nil
+}
+
+// parseName decodes the uncompressed name at offset, returning it lowercased with a trailing dot and the offset after it
+func parseName(msg []byte, offset int) (string, int, error) {
+	var labels []string
+	for {
+		if offset >= len(msg) {
+			return "", 0, errors.New("truncated name")
+		}
+
+		length := int(msg[offset])
+		offset++
+		if length == 0 {
+			break
+		}
+		if length > 63 || offset+length > len(msg) {
+			return "", 0, errors.New("invalid label")
+		}
+
+		labels = append(labels, string(msg[offset:offset+length]))
+		offset += length
+	}
+	return fqdn(strings.Join(labels, ".")), offset, nil
+}
+
+// encodeName returns the wire format of a name with a trailing dot
+func encodeName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+func fqdn(name string) string {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	return name
+}
@@ -0,0 +1,271 @@
+package fakeimgix
+
+import (
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const assetsPath = "/api/v1/assets"
+
+var assetAttributes = []string{"categories", "custom_fields", "description", "tags"}
+
+type asset struct {
+	sourceId        string
+	originPath      string
+	attributes      map[string]interface{}
+	processingUntil time.Time
+}
+
+// SeedAsset stores an asset of an existing source, as if it was found in the origin
+func (s *Server) SeedAsset(sourceId, originPath string, attributes map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.storeAsset(sourceId, originPath, copyMap(attributes))
+}
+
+// Asset returns a copy of the asset attributes as they would be returned by the API
+func (s *Server) Asset(sourceId, originPath string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.assets[assetKey(sourceId, originPath)]
+	if !ok {
+		return nil, false
+	}
+	s.refreshAssetStatus(a)
+	return copyMap(a.attributes), true
+}
+
+// Refreshes returns the number of times an asset was refreshed
+func (s *Server) Refreshes(sourceId, originPath string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.refreshes[assetKey(sourceId, originPath)]
+}
+
+func (s *Server) storeAsset(sourceId, originPath string, attributes map[string]interface{}) *asset {
+	defaults := map[string]interface{}{
+		"categories":    []interface{}{},
+		"content_type":  "image/jpeg",
+		"custom_fields": map[string]interface{}{},
+		"date_created":  s.now().Unix(),
+		"date_modified": s.now().Unix(),
+		"description":   nil,
+		"etag":          fmt.Sprintf("%x", md5.Sum([]byte(sourceId+originPath))),
+		"file_size":     1024,
+		"media_height":  100,
+		"media_width":   100,
+		"status":        "ready",
+		"tags":          []interface{}{},
+	}
+
+	for k, v := range defaults {
+		if _, ok := attributes[k]; !ok {
+			attributes[k] = v
+		}
+	}
+	attributes["origin_path"] = originPath
+	attributes["source_id"] = sourceId
+
+	key := assetKey(sourceId, originPath)
+	if a, ok := s.assets[key]; ok {
+		a.attributes = attributes
+		return a
+	}
+
+	a := &asset{sourceId: sourceId, originPath: originPath, attributes: attributes}
+	s.assetOrder = append(s.assetOrder, key)
+	s.assets[key] = a
+	return a
+}
+
+// refreshAsset starts processing an asset again, it stays processing for the deploy delay
+func (s *Server) refreshAsset(w http.ResponseWriter, path string) {
+	sourceId, originPath := splitAssetPath(path)
+	if _, ok := s.sources[sourceId]; !ok {
+		writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Source not found"})
+		return
+	}
+
+	a, ok := s.assets[assetKey(sourceId, originPath)]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Asset not found"})
+		return
+	}
+
+	s.refreshes[assetKey(sourceId, originPath)]++
+	a.attributes["status"] = "processing"
+	a.processingUntil = s.now().Add(s.deployDelay)
+	s.refreshAssetStatus(a)
+
+	s.writeData(w, http.StatusAccepted, a.resource())
+}
+
+func (s *Server) refreshAssetStatus(a *asset) {
+	if a.attributes["status"] == "processing" && !s.now().Before(a.processingUntil) {
+		a.attributes["status"] = "ready"
+		a.attributes["date_modified"] = s.now().Unix()
+	}
+}
+
+func (s *Server) serveAssets(w http.ResponseWriter, req *http.Request, path string) {
+	sourceId, originPath := splitAssetPath(strings.TrimPrefix(path, assetsPath+"/"))
+	if _, ok := s.sources[sourceId]; !ok {
+		writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Source not found"})
+		return
+	}
+
+	switch {
+	case originPath == "/" && req.Method == http.MethodGet:
+		s.listAssets(w, req, sourceId)
+	case originPath != "/" && req.Method == http.MethodGet:
+		s.getAsset(w, sourceId, originPath)
+	case originPath != "/" && req.Method == http.MethodPatch:
+		s.patchAsset(w, req, sourceId, originPath)
+	default:
+		writeErrors(w, http.StatusNotFound, apiError{
+			Status: "404",
+			Title:  "not_found",
+			Detail: req.Method + " " + path + " is not supported",
+		})
+	}
+}
+
+func (s *Server) listAssets(w http.ResponseWriter, req *http.Request, sourceId string) {
+	query := req.URL.Query()
+	limit, err := strconv.Atoi(query.Get("page[limit]"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	start, _ := strconv.Atoi(query.Get("page[cursor]"))
+
+	var matching []interface{}
+	for _, key := range s.assetOrder {
+		a := s.assets[key]
+		if a.sourceId != sourceId {
+			continue
+		}
+		s.refreshAssetStatus(a)
+
+		if v := query.Get("filter[origin_path]"); v != "" && !strings.HasPrefix(a.originPath, v) {
+			continue
+		}
+		if v := query.Get("filter[content_type]"); v != "" && !strings.HasPrefix(fmt.Sprint(a.attributes["content_type"]), v) {
+			continue
+		}
+		if v := query.Get("filter[tags]"); v != "" && !containsAll(a.attributes["tags"], strings.Split(v, ",")) {
+			continue
+		}
+		if v := query.Get("filter[categories]"); v != "" && !containsAll(a.attributes["categories"], strings.Split(v, ",")) {
+			continue
+		}
+		if !inDateRange(a.attributes["date_created"], query.Get("filter[date_created][gte]"), query.Get("filter[date_created][lt]")) ||
+			!inDateRange(a.attributes["date_modified"], query.Get("filter[date_modified][gte]"), query.Get("filter[date_modified][lt]")) {
+			continue
+		}
+
+		matching = append(matching, a.resource())
+	}
+
+	end := start + limit
+	if end > len(matching) {
+		end = len(matching)
+	}
+	if start > end {
+		start = end
+	}
+
+	cursor := map[string]interface{}{
+		"current":      strconv.Itoa(start),
+		"hasMore":      end < len(matching),
+		"limit":        limit,
+		"totalRecords": len(matching),
+	}
+	if end < len(matching) {
+		cursor["next"] = strconv.Itoa(end)
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+	meta := s.meta()
+	meta["cursor"] = cursor
+	_ = json.NewEncoder(w).Encode(document{Data: append([]interface{}{}, matching[start:end]...), Meta: meta})
+}
+
+func (s *Server) getAsset(w http.ResponseWriter, sourceId, originPath string) {
+	a, ok := s.assets[assetKey(sourceId, originPath)]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Asset not found"})
+		return
+	}
+
+	s.refreshAssetStatus(a)
+	s.writeData(w, http.StatusOK, a.resource())
+}
+
+func (s *Server) patchAsset(w http.ResponseWriter, req *http.Request, sourceId, originPath string) {
+	a, ok := s.assets[assetKey(sourceId, originPath)]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Asset not found"})
+		return
+	}
+
+	body := &sourceBody{}
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil || body.Data.Attributes == nil {
+		writeErrors(w, http.StatusBadRequest, apiError{Status: "400", Title: "invalid_body", Detail: "Invalid JSON:API document"})
+		return
+	}
+
+	for _, k := range assetAttributes {
+		if v, ok := body.Data.Attributes[k]; ok {
+			a.attributes[k] = v
+		}
+	}
+	a.attributes["date_modified"] = s.now().Unix()
+
+	s.writeData(w, http.StatusOK, a.resource())
+}
+
+func (a *asset) resource() map[string]interface{} {
+	return map[string]interface{}{
+		"id":         assetKey(a.sourceId, a.originPath),
+		"type":       "assets",
+		"attributes": copyMap(a.attributes),
+	}
+}
+
+func assetKey(sourceId, originPath string) string {
+	return sourceId + originPath
+}
+
+// splitAssetPath splits <source_id>/<origin path> into the source id and origin path with a leading slash
+func splitAssetPath(path string) (string, string) {
+	i := strings.Index(path, "/")
+	if i < 0 {
+		return path, "/"
+	}
+	return path[:i], path[i:]
+}
+
+// inDateRange checks a unix timestamp attribute against gte and lt filter values, empty values aren't checked
+func inDateRange(v interface{}, gte, lt string) bool {
+	timestamp, err := strconv.ParseFloat(fmt.Sprint(v), 64)
+	if err != nil {
+		return gte == "" && lt == ""
+	}
+
+	if from, err := strconv.ParseFloat(gte, 64); err == nil && timestamp < from {
+		return false
+	}
+	if to, err := strconv.ParseFloat(lt, 64); err == nil && timestamp >= to {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package fakeimgix
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const deploymentsSuffix = "/deployments"
+
+type deployment struct {
+	id         string
+	attributes map[string]interface{}
+}
+
+// Deployments returns copies of all deployments of a source, newest first
+func (s *Server) Deployments(sourceId string) []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, ok := s.sources[sourceId]
+	if !ok {
+		return nil
+	}
+
+	s.refresh(src)
+	var deployments []map[string]interface{}
+	for i := len(src.deployments) - 1; i >= 0; i-- {
+		deployments = append(deployments, copyMap(src.deployments[i].attributes))
+	}
+	return deployments
+}
+
+// recordDeployment adds the current deployment settings of the source to its history
+func (s *Server) recordDeployment(src *source, status string) {
+	if n := len(src.deployments); n > 0 && src.deployments[n-1].attributes["deployment_status"] == "deploying" {
+		src.deployments[n-1].attributes["deployment_status"] = "superseded"
+	}
+
+	settings := copyMap(deploymentOf(src.attributes))
+	d := &deployment{
+		id: fmt.Sprintf("%s-%d", src.id, len(src.deployments)+1),
+		attributes: map[string]interface{}{
+			"annotation":        settings["annotation"],
+			"date_created":      s.now().Unix(),
+			"date_deployed":     nil,
+			"deployment":        settings,
+			"deployment_status": status,
+			"source_id":         src.id,
+		},
+	}
+	if status == "deployed" {
+		d.attributes["date_deployed"] = s.now().Unix()
+	}
+
+	src.deployments = append(src.deployments, d)
+}
+
+func (s *Server) listDeployments(w http.ResponseWriter, path string) {
+	id := strings.TrimSuffix(strings.TrimPrefix(path, sourcesPath+"/"), deploymentsSuffix)
+	src, ok := s.sources[id]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Source not found"})
+		return
+	}
+
+	s.refresh(src)
+	data := []interface{}{}
+	for i := len(src.deployments) - 1; i >= 0; i-- {
+		data = append(data, src.deployments[i].resource())
+	}
+
+	s.writeData(w, http.StatusOK, data)
+}
+
+func (d *deployment) resource() map[string]interface{} {
+	return map[string]interface{}{
+		"id":         d.id,
+		"type":       "deployments",
+		"attributes": copyMap(d.attributes),
+	}
+}
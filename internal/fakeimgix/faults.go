@@ -0,0 +1,72 @@
+package fakeimgix
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Fault makes the server fail matching requests before they are handled
+type Fault struct {
+	// Method of matched requests, any method when empty
+	Method string
+	// Path prefix of matched requests, any path when empty
+	Path string
+	// Status code returned instead of the real response, e.g. 429 or 503
+	Status int
+	// RetryAfter is sent in the Retry-After header when set
+	RetryAfter time.Duration
+	// Times limits how many requests fail, every matching request fails when 0
+	Times int
+}
+
+// InjectFault adds a fault which is applied before any previously injected one
+func (s *Server) InjectFault(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fault := f
+	s.faults = append([]*Fault{&fault}, s.faults...)
+}
+
+// ClearFaults removes all injected faults
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+func (s *Server) matchFault(req *http.Request) *Fault {
+	for i, f := range s.faults {
+		if f.Method != "" && f.Method != req.Method {
+			continue
+		}
+		if !strings.HasPrefix(req.URL.Path, f.Path) {
+			continue
+		}
+
+		if f.Times > 0 {
+			f.Times--
+			if f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (f *Fault) write(w http.ResponseWriter) {
+	if f.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(f.RetryAfter.Seconds())))
+	}
+
+	writeErrors(w, f.Status, apiError{
+		Status: strconv.Itoa(f.Status),
+		Title:  "injected_fault",
+		Detail: fmt.Sprintf("Injected %s", http.StatusText(f.Status)),
+	})
+}
@@ -0,0 +1,75 @@
+package fakeimgix
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Purge is a purge request accepted by the server
+type Purge struct {
+	Id   string
+	Url  string
+	Time time.Time
+}
+
+// Purges returns all accepted purge requests in order
+func (s *Server) Purges() []Purge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Purge(nil), s.purges...)
+}
+
+func (s *Server) purge(w http.ResponseWriter, req *http.Request) {
+	body := &sourceBody{}
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil || body.Data.Type != "purges" {
+		writeErrors(w, http.StatusBadRequest, apiError{Status: "400", Title: "invalid_body", Detail: "Invalid JSON:API document"})
+		return
+	}
+
+	rawUrl, _ := body.Data.Attributes["url"].(string)
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Host == "" {
+		writeErrors(w, http.StatusBadRequest, apiError{Status: "400", Title: "url", Detail: "Invalid purge URL"})
+		return
+	}
+
+	if !s.servesHost(u.Hostname()) {
+		writeErrors(w, http.StatusBadRequest, apiError{
+			Status: "400",
+			Title:  "url",
+			Detail: fmt.Sprintf("Host %s doesn't belong to any source", u.Hostname()),
+		})
+		return
+	}
+
+	p := Purge{
+		Id:   fmt.Sprintf("purge-%d", len(s.purges)+1),
+		Url:  rawUrl,
+		Time: s.now(),
+	}
+	s.purges = append(s.purges, p)
+
+	s.writeData(w, http.StatusCreated, map[string]interface{}{
+		"id":         p.Id,
+		"type":       "purges",
+		"attributes": map[string]interface{}{"url": p.Url},
+	})
+}
+
+func (s *Server) servesHost(host string) bool {
+	for _, id := range s.order {
+		deployment := deploymentOf(s.sources[id].attributes)
+		if containsString(deployment["custom_domains"], host) {
+			return true
+		}
+		if strings.HasSuffix(host, ".imgix.net") && containsString(deployment["imgix_subdomains"], strings.TrimSuffix(host, ".imgix.net")) {
+			return true
+		}
+	}
+	return false
+}
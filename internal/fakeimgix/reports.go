@@ -0,0 +1,141 @@
+package fakeimgix
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	reportsPath     = "/api/v1/reports"
+	reportFilesPath = reportsPath + "/files"
+)
+
+// UsageRow is a single day of usage of a source in a report
+type UsageRow struct {
+	Date          string  `json:"date"`
+	SourceId      string  `json:"source_id"`
+	Bandwidth     int64   `json:"bandwidth"`
+	Requests      int64   `json:"requests"`
+	MasterImages  int64   `json:"master_images"`
+	CacheHitRatio float64 `json:"cache_hit_ratio"`
+}
+
+type report struct {
+	id          string
+	sourceId    string
+	periodStart string
+	periodEnd   string
+	format      string
+	rows        []UsageRow
+}
+
+// SeedReport stores a usage report in csv or json format and returns its id.
+// Reports without a source id cover the whole account.
+func (s *Server) SeedReport(sourceId, periodStart, periodEnd, format string, rows []UsageRow) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := &report{
+		id:          strconv.Itoa(len(s.reports) + 1),
+		sourceId:    sourceId,
+		periodStart: periodStart,
+		periodEnd:   periodEnd,
+		format:      format,
+		rows:        rows,
+	}
+	s.reports = append(s.reports, r)
+	return r.id
+}
+
+func (s *Server) listReports(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	data := []interface{}{}
+	for _, r := range s.reports {
+		if v := query.Get("filter[report_type]"); v != "" && v != "usage" {
+			continue
+		}
+		if v := query.Get("filter[source_id]"); r.sourceId != v {
+			continue
+		}
+		// dates are in YYYY-MM-DD format, so they can be compared as strings
+		if v := query.Get("filter[period_start]"); v != "" && r.periodEnd < v {
+			continue
+		}
+		if v := query.Get("filter[period_end]"); v != "" && r.periodStart > v {
+			continue
+		}
+
+		data = append(data, s.reportResource(r))
+	}
+
+	s.writeData(w, http.StatusOK, data)
+}
+
+// serveReportFile returns report content, file urls are signed so no API key is needed
+func (s *Server) serveReportFile(w http.ResponseWriter, id string) {
+	for _, r := range s.reports {
+		if r.id != id {
+			continue
+		}
+
+		if r.format == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(r.rows)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		_, _ = w.Write(r.csv())
+		return
+	}
+
+	writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Report not found"})
+}
+
+func (s *Server) reportResource(r *report) map[string]interface{} {
+	var sourceId interface{}
+	if r.sourceId != "" {
+		sourceId = r.sourceId
+	}
+
+	return map[string]interface{}{
+		"id":   r.id,
+		"type": "reports",
+		"attributes": map[string]interface{}{
+			"format":       r.format,
+			"period_end":   r.periodEnd,
+			"period_start": r.periodStart,
+			"report_type":  "usage",
+			"source_id":    sourceId,
+			"url":          fmt.Sprintf("%s%s/%s.%s", s.URL, reportFilesPath, r.id, r.format),
+		},
+	}
+}
+
+func (r *report) csv() []byte {
+	var b bytes.Buffer
+	writer := csv.NewWriter(&b)
+	_ = writer.Write([]string{"date", "source_id", "bandwidth", "requests", "master_images", "cache_hit_ratio"})
+	for _, row := range r.rows {
+		_ = writer.Write([]string{
+			row.Date,
+			row.SourceId,
+			strconv.FormatInt(row.Bandwidth, 10),
+			strconv.FormatInt(row.Requests, 10),
+			strconv.FormatInt(row.MasterImages, 10),
+			strconv.FormatFloat(row.CacheHitRatio, 'f', -1, 64),
+		})
+	}
+	writer.Flush()
+	return b.Bytes()
+}
+
+func reportFileId(path string) string {
+	name := strings.TrimPrefix(path, reportFilesPath+"/")
+	return strings.TrimSuffix(strings.TrimSuffix(name, ".csv"), ".json")
+}
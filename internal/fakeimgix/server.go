@@ -0,0 +1,206 @@
+// Package fakeimgix implements an in-process, stateful imitation of the imgix Management API
+// used by the provider's acceptance tests to run without network access.
+package fakeimgix
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultApiKey = "fake-api-key"
+
+	sourcesPath = "/api/v1/sources"
+	uploadPath  = sourcesPath + "/upload"
+	refreshPath = assetsPath + "/refresh"
+	purgePath   = "/api/v1/purge"
+)
+
+// Server is a fake imgix API listening on a local address
+type Server struct {
+	URL string
+
+	mu          sync.Mutex
+	http        *httptest.Server
+	apiKey      string
+	deployDelay time.Duration
+	now         func() time.Time
+
+	requests   int
+	nextId     int
+	sources    map[string]*source
+	order      []string
+	assets     map[string]*asset
+	assetOrder []string
+	objects    map[string]Object
+	uploads    map[string]int
+	refreshes  map[string]int
+	purges     []Purge
+	reports    []*report
+	faults     []*Fault
+	pageSize   int
+
+	skipUploadIndexing bool
+}
+
+type Option func(*Server)
+
+// WithApiKey sets the key which has to be sent as a bearer token. Defaults to DefaultApiKey.
+func WithApiKey(key string) Option {
+	return func(s *Server) {
+		s.apiKey = key
+	}
+}
+
+// WithDeployDelay sets how long a source stays in the deploying state after every change
+// and how long an asset stays processing after a refresh
+func WithDeployDelay(delay time.Duration) Option {
+	return func(s *Server) {
+		s.deployDelay = delay
+	}
+}
+
+// WithPageSize sets how many sources are listed per page, all sources are listed on one page by default
+func WithPageSize(size int) Option {
+	return func(s *Server) {
+		s.pageSize = size
+	}
+}
+
+// WithoutUploadIndexing stops uploaded files from getting an asset, like uploads imgix hasn't indexed yet
+func WithoutUploadIndexing() Option {
+	return func(s *Server) {
+		s.skipUploadIndexing = true
+	}
+}
+
+// WithClock replaces the time source used for deployment transitions
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// New starts a fake API server. It has to be stopped with Close.
+func New(options ...Option) *Server {
+	s := &Server{
+		apiKey:    DefaultApiKey,
+		now:       time.Now,
+		sources:   map[string]*source{},
+		assets:    map[string]*asset{},
+		objects:   map[string]Object{},
+		uploads:   map[string]int{},
+		refreshes: map[string]int{},
+	}
+
+	for _, o := range options {
+		o(s)
+	}
+
+	s.http = httptest.NewServer(s)
+	s.URL = s.http.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.http.Close()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	w.Header().Set("X-Request-Id", fmt.Sprintf("fake-request-%d", s.requests))
+
+	if f := s.matchFault(req); f != nil {
+		f.write(w)
+		return
+	}
+
+	if strings.HasPrefix(req.URL.Path, reportFilesPath+"/") && req.Method == http.MethodGet {
+		s.serveReportFile(w, reportFileId(req.URL.Path))
+		return
+	}
+
+	if req.Header.Get("Authorization") != "Bearer "+s.apiKey {
+		writeErrors(w, http.StatusUnauthorized, apiError{
+			Status: "401",
+			Title:  "unauthorized",
+			Detail: "Invalid API key",
+		})
+		return
+	}
+
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	switch {
+	case path == sourcesPath && req.Method == http.MethodGet:
+		s.listSources(w, req)
+	case path == sourcesPath && req.Method == http.MethodPost:
+		s.createSource(w, req)
+	case strings.HasPrefix(path, uploadPath+"/") && req.Method == http.MethodPost:
+		s.uploadObject(w, req, strings.TrimPrefix(path, uploadPath+"/"))
+	case strings.HasPrefix(path, sourcesPath+"/") && strings.HasSuffix(path, deploymentsSuffix) && req.Method == http.MethodGet:
+		s.listDeployments(w, path)
+	case strings.HasPrefix(path, sourcesPath+"/") && req.Method == http.MethodGet:
+		s.getSource(w, strings.TrimPrefix(path, sourcesPath+"/"))
+	case strings.HasPrefix(path, sourcesPath+"/") && req.Method == http.MethodPatch:
+		s.patchSource(w, req, strings.TrimPrefix(path, sourcesPath+"/"))
+	case strings.HasPrefix(path, refreshPath+"/") && req.Method == http.MethodPost:
+		s.refreshAsset(w, strings.TrimPrefix(path, refreshPath+"/"))
+	case strings.HasPrefix(path, assetsPath+"/"):
+		s.serveAssets(w, req, path)
+	case path == reportsPath && req.Method == http.MethodGet:
+		s.listReports(w, req)
+	case path == purgePath && req.Method == http.MethodPost:
+		s.purge(w, req)
+	default:
+		writeErrors(w, http.StatusNotFound, apiError{
+			Status: "404",
+			Title:  "not_found",
+			Detail: req.Method + " " + path + " is not supported",
+		})
+	}
+}
+
+type apiError struct {
+	Detail string `json:"detail"`
+	Status string `json:"status"`
+	Title  string `json:"title"`
+}
+
+type document struct {
+	Data  interface{}            `json:"data"`
+	Links map[string]interface{} `json:"links,omitempty"`
+	Meta  map[string]interface{} `json:"meta"`
+}
+
+func (s *Server) meta() map[string]interface{} {
+	return map[string]interface{}{
+		"authentication": map[string]interface{}{
+			"authorized": true,
+			"clientId":   nil,
+			"mode":       "PUBLIC_APIKEY",
+			"modeTitle":  "Public API Key",
+			"tag":        "fake@example.com",
+			"user":       nil,
+		},
+	}
+}
+
+func (s *Server) writeData(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(document{Data: data, Meta: s.meta()})
+}
+
+func writeErrors(w http.ResponseWriter, status int, errors ...apiError) {
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"errors": errors})
+}
@@ -0,0 +1,295 @@
+package fakeimgix
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func doRequest(t *testing.T, s *Server, method, path string, body interface{}) (*http.Response, map[string]interface{}) {
+	var b bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&b).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req, err := http.NewRequest(method, s.URL+path, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+DefaultApiKey)
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	doc := map[string]interface{}{}
+	_ = json.NewDecoder(res.Body).Decode(&doc)
+	return res, doc
+}
+
+func sourceDocument(name string, subdomains ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"data": map[string]interface{}{
+			"type": "sources",
+			"attributes": map[string]interface{}{
+				"name": name,
+				"deployment": map[string]interface{}{
+					"type":             "webfolder",
+					"imgix_subdomains": subdomains,
+				},
+			},
+		},
+	}
+}
+
+func attributesOf(doc map[string]interface{}) map[string]interface{} {
+	return doc["data"].(map[string]interface{})["attributes"].(map[string]interface{})
+}
+
+func TestDeploymentStatusTransitions(t *testing.T) {
+	clock := &testClock{now: time.Unix(1612274615, 0)}
+	s := New(WithDeployDelay(time.Minute), WithClock(clock.Now))
+	defer s.Close()
+
+	res, doc := doRequest(t, s, http.MethodPost, sourcesPath, sourceDocument("source1", "example-1"))
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d", res.StatusCode)
+	}
+
+	id := doc["data"].(map[string]interface{})["id"].(string)
+	if status := attributesOf(doc)["deployment_status"]; status != "deploying" {
+		t.Errorf("new source should be deploying, got %v", status)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	_, doc = doRequest(t, s, http.MethodGet, sourcesPath+"/"+id, nil)
+	if status := attributesOf(doc)["deployment_status"]; status != "deployed" {
+		t.Errorf("source should be deployed after delay, got %v", status)
+	}
+
+	_, doc = doRequest(t, s, http.MethodPatch, sourcesPath+"/"+id, map[string]interface{}{
+		"data": map[string]interface{}{
+			"id":         id,
+			"type":       "sources",
+			"attributes": map[string]interface{}{"enabled": false},
+		},
+	})
+	if status := attributesOf(doc)["deployment_status"]; status != "deploying" {
+		t.Errorf("source should keep deploying until it is disabled, got %v", status)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	_, doc = doRequest(t, s, http.MethodGet, sourcesPath+"/"+id, nil)
+	if status := attributesOf(doc)["deployment_status"]; status != "disabled" {
+		t.Errorf("source should be disabled after delay, got %v", status)
+	}
+}
+
+func TestDeploymentHistory(t *testing.T) {
+	clock := &testClock{now: time.Unix(1612274615, 0)}
+	s := New(WithDeployDelay(time.Minute), WithClock(clock.Now))
+	defer s.Close()
+
+	_, doc := doRequest(t, s, http.MethodPost, sourcesPath, sourceDocument("source1", "example-1"))
+	id := doc["data"].(map[string]interface{})["id"].(string)
+
+	clock.now = clock.now.Add(time.Minute)
+	doRequest(t, s, http.MethodPatch, sourcesPath+"/"+id, map[string]interface{}{
+		"data": map[string]interface{}{
+			"id":   id,
+			"type": "sources",
+			"attributes": map[string]interface{}{
+				"deployment": map[string]interface{}{"annotation": "second"},
+			},
+		},
+	})
+
+	res, doc := doRequest(t, s, http.MethodGet, sourcesPath+"/"+id+deploymentsSuffix, nil)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", res.StatusCode)
+	}
+
+	data := doc["data"].([]interface{})
+	if len(data) != 2 {
+		t.Fatalf("expected 2 deployments, got %d", len(data))
+	}
+
+	latest := data[0].(map[string]interface{})["attributes"].(map[string]interface{})
+	first := data[1].(map[string]interface{})["attributes"].(map[string]interface{})
+	if latest["annotation"] != "second" || latest["deployment_status"] != "deploying" {
+		t.Errorf("unexpected latest deployment: %v", latest)
+	}
+	if first["deployment_status"] != "deployed" || first["date_deployed"] == nil {
+		t.Errorf("first deployment should be deployed: %v", first)
+	}
+}
+
+func TestAssetRefreshTransitions(t *testing.T) {
+	clock := &testClock{now: time.Unix(1612274615, 0)}
+	s := New(WithDeployDelay(time.Minute), WithClock(clock.Now))
+	defer s.Close()
+
+	id := s.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+		},
+	})
+	s.SeedAsset(id, "/image.png", map[string]interface{}{})
+
+	res, doc := doRequest(t, s, http.MethodPost, refreshPath+"/"+id+"/image.png", nil)
+	if res.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", res.StatusCode)
+	}
+	if status := attributesOf(doc)["status"]; status != "processing" {
+		t.Errorf("refreshed asset should be processing, got %v", status)
+	}
+
+	clock.now = clock.now.Add(time.Minute)
+	_, doc = doRequest(t, s, http.MethodGet, assetsPath+"/"+id+"/image.png", nil)
+	if status := attributesOf(doc)["status"]; status != "ready" {
+		t.Errorf("asset should be ready after delay, got %v", status)
+	}
+
+	res, _ = doRequest(t, s, http.MethodPost, refreshPath+"/"+id+"/missing.png", nil)
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for missing asset, got %d", res.StatusCode)
+	}
+}
+
+func TestValidationErrors(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	s.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+		},
+	})
+
+	cases := map[string]map[string]interface{}{
+		"taken subdomain":   sourceDocument("source2", "example-1"),
+		"imgix.net suffix":  sourceDocument("source2", "example-2.imgix.net"),
+		"missing subdomain": sourceDocument("source2"),
+		"missing name":      sourceDocument("", "example-2"),
+	}
+
+	for name, doc := range cases {
+		t.Run(name, func(t *testing.T) {
+			res, body := doRequest(t, s, http.MethodPost, sourcesPath, doc)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", res.StatusCode)
+			}
+
+			if errs, ok := body["errors"].([]interface{}); !ok || len(errs) == 0 {
+				t.Error("expected JSON:API errors in response")
+			}
+		})
+	}
+}
+
+func TestFaultInjection(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	s.InjectFault(Fault{
+		Method:     http.MethodGet,
+		Path:       sourcesPath,
+		Status:     http.StatusTooManyRequests,
+		RetryAfter: 2 * time.Second,
+		Times:      2,
+	})
+
+	for i := 0; i < 2; i++ {
+		res, _ := doRequest(t, s, http.MethodGet, sourcesPath, nil)
+		if res.StatusCode != http.StatusTooManyRequests {
+			t.Errorf("request %d should fail with 429, got %d", i, res.StatusCode)
+		}
+		if res.Header.Get("Retry-After") != "2" {
+			t.Errorf("request %d should have Retry-After header", i)
+		}
+	}
+
+	if res, _ := doRequest(t, s, http.MethodGet, sourcesPath, nil); res.StatusCode != http.StatusOK {
+		t.Errorf("fault should be exhausted, got %d", res.StatusCode)
+	}
+}
+
+func TestPurges(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	s.SeedSource(map[string]interface{}{
+		"name": "source1",
+		"deployment": map[string]interface{}{
+			"type":             "webfolder",
+			"imgix_subdomains": []string{"example-1"},
+		},
+	})
+
+	purge := func(url string) int {
+		res, _ := doRequest(t, s, http.MethodPost, purgePath, map[string]interface{}{
+			"data": map[string]interface{}{
+				"type":       "purges",
+				"attributes": map[string]interface{}{"url": url},
+			},
+		})
+		return res.StatusCode
+	}
+
+	if status := purge("https://example-1.imgix.net/image.png"); status != http.StatusCreated {
+		t.Errorf("purge of known host should succeed, got %d", status)
+	}
+
+	if status := purge("https://unknown.imgix.net/image.png"); status != http.StatusBadRequest {
+		t.Errorf("purge of unknown host should fail, got %d", status)
+	}
+
+	if purges := s.Purges(); len(purges) != 1 || purges[0].Url != "https://example-1.imgix.net/image.png" {
+		t.Errorf("unexpected purges: %v", purges)
+	}
+}
+
+func TestReports(t *testing.T) {
+	s := New()
+	defer s.Close()
+
+	s.SeedReport("", "2021-01-01", "2021-01-31", "csv", []UsageRow{{Date: "2021-01-01", Bandwidth: 10, Requests: 2}})
+	s.SeedReport("", "2021-02-01", "2021-02-28", "json", nil)
+
+	_, doc := doRequest(t, s, http.MethodGet, reportsPath+"?filter[period_start]=2021-01-15&filter[period_end]=2021-01-20", nil)
+	data := doc["data"].([]interface{})
+	if len(data) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(data))
+	}
+
+	url := data[0].(map[string]interface{})["attributes"].(map[string]interface{})["url"].(string)
+	res, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var b bytes.Buffer
+	_, _ = b.ReadFrom(res.Body)
+	if res.StatusCode != http.StatusOK || !bytes.HasPrefix(b.Bytes(), []byte("date,source_id,bandwidth")) {
+		t.Errorf("unexpected report file %d: %s", res.StatusCode, b.String())
+	}
+}
@@ -0,0 +1,456 @@
+package fakeimgix
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	deploymentTypes   = []string{"azure", "gcs", "s3", "s3_compatible", "webfolder", "webproxy"}
+	cacheTtlBehaviors = []string{"respect_origin", "override_origin", "enforce_minimum"}
+
+	// attributes ignored in PATCH requests, deployment is merged separately
+	readOnlyAttributes = map[string]bool{
+		"date_deployed":     true,
+		"deployment":        true,
+		"deployment_status": true,
+		"secure_url_token":  true,
+	}
+)
+
+type source struct {
+	id             string
+	attributes     map[string]interface{}
+	s3SecretKey    string
+	deployingUntil time.Time
+	deployments    []*deployment
+}
+
+type sourceBody struct {
+	Data struct {
+		Id         string                 `json:"id"`
+		Type       string                 `json:"type"`
+		Attributes map[string]interface{} `json:"attributes"`
+	} `json:"data"`
+}
+
+// SeedSource stores an already deployed source without validation and returns its id
+func (s *Server) SeedSource(attributes map[string]interface{}) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src := s.newSource(copyMap(attributes))
+	src.attributes["deployment_status"] = "deployed"
+	src.attributes["date_deployed"] = s.now().Unix()
+	if src.attributes["enabled"] == false {
+		src.attributes["deployment_status"] = "disabled"
+	}
+	s.recordDeployment(src, "deployed")
+	return src.id
+}
+
+// Source returns a copy of the source attributes as they would be returned by the API
+func (s *Server) Source(id string) (map[string]interface{}, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, ok := s.sources[id]
+	if !ok {
+		return nil, false
+	}
+
+	s.refresh(src)
+	return copyMap(src.attributes), true
+}
+
+// S3SecretKey returns the last S3 secret key sent for the source, which the API never returns
+func (s *Server) S3SecretKey(id string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if src, ok := s.sources[id]; ok {
+		return src.s3SecretKey
+	}
+	return ""
+}
+
+// UpdateSource allows tests to change a source behind the provider's back
+func (s *Server) UpdateSource(id string, update func(attributes map[string]interface{})) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if src, ok := s.sources[id]; ok {
+		update(src.attributes)
+	}
+}
+
+func (s *Server) newSource(attributes map[string]interface{}) *source {
+	s.nextId++
+	src := &source{
+		id:         fmt.Sprintf("%024x", 0x6014302237535900+s.nextId),
+		attributes: attributes,
+	}
+
+	if _, ok := attributes["enabled"]; !ok {
+		attributes["enabled"] = true
+	}
+
+	deployment := deploymentOf(attributes)
+	applyDeploymentDefaults(deployment)
+	if secret, ok := deployment["s3_secret_key"].(string); ok {
+		src.s3SecretKey = secret
+	}
+	delete(deployment, "s3_secret_key")
+	s.setSecureUrlToken(src)
+
+	s.sources[src.id] = src
+	s.order = append(s.order, src.id)
+	return src
+}
+
+func (s *Server) listSources(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	data := []interface{}{}
+	for _, id := range s.order {
+		src := s.sources[id]
+		s.refresh(src)
+		deployment := deploymentOf(src.attributes)
+
+		if v := query.Get("filter[name]"); v != "" && src.attributes["name"] != v {
+			continue
+		}
+		if v := query.Get("filter[enabled]"); v != "" && fmt.Sprint(src.attributes["enabled"]) != v {
+			continue
+		}
+		if v := query.Get("filter[deployment.imgix_subdomains]"); v != "" && !containsString(deployment["imgix_subdomains"], v) {
+			continue
+		}
+		if v := query.Get("filter[deployment.custom_domains]"); v != "" && !containsString(deployment["custom_domains"], v) {
+			continue
+		}
+
+		data = append(data, src.resource())
+	}
+
+	if s.pageSize <= 0 {
+		s.writeData(w, http.StatusOK, data)
+		return
+	}
+
+	number, _ := strconv.Atoi(query.Get("page[number]"))
+	if number < 1 {
+		number = 1
+	}
+	start, end := (number-1)*s.pageSize, number*s.pageSize
+	if start > len(data) {
+		start = len(data)
+	}
+	if end > len(data) {
+		end = len(data)
+	}
+
+	links := map[string]interface{}{}
+	if end < len(data) {
+		query.Set("page[number]", strconv.Itoa(number+1))
+		links["next"] = fmt.Sprintf("http://%s%s?%s", req.Host, sourcesPath, query.Encode())
+	}
+
+	w.Header().Set("Content-Type", "application/vnd.api+json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(document{Data: data[start:end], Links: links, Meta: s.meta()})
+}
+
+func (s *Server) createSource(w http.ResponseWriter, req *http.Request) {
+	body := &sourceBody{}
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil || body.Data.Attributes == nil {
+		writeErrors(w, http.StatusBadRequest, apiError{Status: "400", Title: "invalid_body", Detail: "Invalid JSON:API document"})
+		return
+	}
+
+	attributes := body.Data.Attributes
+	if errs := s.validate("", attributes, true); len(errs) > 0 {
+		writeErrors(w, http.StatusBadRequest, errs...)
+		return
+	}
+
+	// sources are always enabled when created
+	attributes["enabled"] = true
+	src := s.newSource(attributes)
+	s.startDeployment(src)
+	s.writeData(w, http.StatusCreated, src.resource())
+}
+
+func (s *Server) getSource(w http.ResponseWriter, id string) {
+	src, ok := s.sources[id]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Source not found"})
+		return
+	}
+
+	s.refresh(src)
+	s.writeData(w, http.StatusOK, src.resource())
+}
+
+func (s *Server) patchSource(w http.ResponseWriter, req *http.Request, id string) {
+	src, ok := s.sources[id]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Source not found"})
+		return
+	}
+
+	body := &sourceBody{}
+	if err := json.NewDecoder(req.Body).Decode(body); err != nil || body.Data.Attributes == nil {
+		writeErrors(w, http.StatusBadRequest, apiError{Status: "400", Title: "invalid_body", Detail: "Invalid JSON:API document"})
+		return
+	}
+
+	s.refresh(src)
+	patched := copyMap(src.attributes)
+	for k, v := range body.Data.Attributes {
+		if !readOnlyAttributes[k] {
+			patched[k] = v
+		}
+	}
+
+	changes, _ := body.Data.Attributes["deployment"].(map[string]interface{})
+	if _, ok := changes["allows_upload"]; ok {
+		writeErrors(w, http.StatusBadRequest, apiError{Status: "400", Title: "allows_upload", Detail: "allows_upload is read-only"})
+		return
+	}
+	deployment := deploymentOf(patched)
+	for k, v := range changes {
+		deployment[k] = v
+	}
+
+	if errs := s.validate(id, patched, false); len(errs) > 0 {
+		writeErrors(w, http.StatusBadRequest, errs...)
+		return
+	}
+
+	if secret, ok := deployment["s3_secret_key"].(string); ok && secret != "" {
+		src.s3SecretKey = secret
+	}
+	delete(deployment, "s3_secret_key")
+
+	wasEnabled := src.attributes["enabled"] != false
+	src.attributes = patched
+	s.setSecureUrlToken(src)
+
+	switch enabled := patched["enabled"] != false; {
+	case !enabled && wasEnabled:
+		// sources keep serving for a while after they are disabled
+		src.attributes["deployment_status"] = "deploying"
+		src.deployingUntil = s.now().Add(s.deployDelay)
+	case !enabled:
+		src.attributes["deployment_status"] = "disabled"
+	case changes != nil || !wasEnabled:
+		s.startDeployment(src)
+	}
+
+	s.writeData(w, http.StatusOK, src.resource())
+}
+
+func (s *Server) startDeployment(src *source) {
+	src.attributes["deployment_status"] = "deploying"
+	src.deployingUntil = s.now().Add(s.deployDelay)
+	s.recordDeployment(src, "deploying")
+}
+
+// refresh finishes deployments which are deploying longer than the configured delay
+func (s *Server) refresh(src *source) {
+	if src.attributes["deployment_status"] == "deploying" && !s.now().Before(src.deployingUntil) {
+		if src.attributes["enabled"] == false {
+			src.attributes["deployment_status"] = "disabled"
+			return
+		}
+
+		src.attributes["deployment_status"] = "deployed"
+		src.attributes["date_deployed"] = s.now().Unix()
+
+		if n := len(src.deployments); n > 0 && src.deployments[n-1].attributes["deployment_status"] == "deploying" {
+			src.deployments[n-1].attributes["deployment_status"] = "deployed"
+			src.deployments[n-1].attributes["date_deployed"] = s.now().Unix()
+		}
+	}
+}
+
+func (s *Server) setSecureUrlToken(src *source) {
+	if deploymentOf(src.attributes)["secure_url_enabled"] == true {
+		if _, ok := src.attributes["secure_url_token"]; !ok {
+			src.attributes["secure_url_token"] = fmt.Sprintf("token-%s", src.id)
+		}
+	} else {
+		delete(src.attributes, "secure_url_token")
+	}
+}
+
+func (s *Server) validate(id string, attributes map[string]interface{}, create bool) []apiError {
+	var errs []apiError
+	invalid := func(title, detail string, args ...interface{}) {
+		errs = append(errs, apiError{Status: "400", Title: title, Detail: fmt.Sprintf(detail, args...)})
+	}
+
+	if name, _ := attributes["name"].(string); name == "" {
+		invalid("name", "Name can't be empty")
+	}
+
+	deployment, ok := attributes["deployment"].(map[string]interface{})
+	if !ok {
+		invalid("deployment", "Deployment is required")
+		return errs
+	}
+
+	deploymentType, _ := deployment["type"].(string)
+	if !containsString(deploymentTypes, deploymentType) {
+		invalid("type", "Invalid deployment type %q", deploymentType)
+	}
+
+	if behavior, _ := deployment["cache_ttl_behavior"].(string); behavior != "" && !containsString(cacheTtlBehaviors, behavior) {
+		invalid("cache_ttl_behavior", "Invalid cache TTL behavior %q", behavior)
+	}
+
+	subdomains := toStrings(deployment["imgix_subdomains"])
+	if len(subdomains) == 0 {
+		invalid("imgix_subdomains", "At least one imgix subdomain is required")
+	}
+
+	for _, subdomain := range subdomains {
+		if strings.HasSuffix(subdomain, "imgix.net") {
+			invalid("imgix_subdomains", "Subdomain %s can't contain imgix.net suffix", subdomain)
+		} else if owner := s.findSource(id, "imgix_subdomains", subdomain); owner != "" {
+			invalid("imgix_subdomains", "Subdomain %s is already used by source %s", subdomain, owner)
+		}
+	}
+
+	for _, domain := range toStrings(deployment["custom_domains"]) {
+		if owner := s.findSource(id, "custom_domains", domain); owner != "" {
+			invalid("custom_domains", "Custom domain %s is already used by source %s", domain, owner)
+		}
+	}
+
+	if deploymentType == "s3_compatible" {
+		if v, _ := deployment["s3_endpoint"].(string); v == "" {
+			invalid("s3_endpoint", "S3 endpoint is required")
+		}
+	}
+
+	if deploymentType == "s3" || deploymentType == "s3_compatible" {
+		if v, _ := deployment["s3_bucket"].(string); v == "" {
+			invalid("s3_bucket", "S3 bucket is required")
+		}
+		if v, _ := deployment["s3_access_key"].(string); v == "" {
+			invalid("aws_access_key", "AWS access key is required")
+		}
+		// updates without the key keep the stored secret, but null doesn't
+		switch secret, sent := deployment["s3_secret_key"]; {
+		case create && (secret == nil || secret == ""):
+			invalid("aws_secret_key", "AWS secret key is required")
+		case sent && secret == nil:
+			invalid("aws_secret_key", "AWS secret key can't be null")
+		}
+	}
+
+	return errs
+}
+
+// findSource returns id of another source which uses value in the given deployment list field
+func (s *Server) findSource(exceptId, field, value string) string {
+	for _, id := range s.order {
+		if id == exceptId {
+			continue
+		}
+
+		src := s.sources[id]
+		if src.attributes["enabled"] != false && containsString(deploymentOf(src.attributes)[field], value) {
+			return id
+		}
+	}
+	return ""
+}
+
+func (src *source) resource() map[string]interface{} {
+	return map[string]interface{}{
+		"id":         src.id,
+		"type":       "sources",
+		"attributes": copyMap(src.attributes),
+	}
+}
+
+func deploymentOf(attributes map[string]interface{}) map[string]interface{} {
+	deployment, ok := attributes["deployment"].(map[string]interface{})
+	if !ok {
+		deployment = map[string]interface{}{}
+		attributes["deployment"] = deployment
+	}
+	return deployment
+}
+
+func applyDeploymentDefaults(deployment map[string]interface{}) {
+	defaults := map[string]interface{}{
+		"allows_upload":           false,
+		"annotation":              "",
+		"cache_ttl_behavior":      "respect_origin",
+		"cache_ttl_error":         300,
+		"cache_ttl_value":         31536000,
+		"crossdomain_xml_enabled": false,
+		"custom_domains":          []interface{}{},
+		"default_params":          map[string]interface{}{},
+		"image_error":             nil,
+		"image_error_append_qs":   false,
+		"image_missing":           nil,
+		"image_missing_append_qs": false,
+		"secure_url_enabled":      false,
+	}
+
+	for k, v := range defaults {
+		if _, ok := deployment[k]; !ok {
+			deployment[k] = v
+		}
+	}
+}
+
+// copyMap deep copies JSON-like values through a JSON round trip
+func copyMap(m map[string]interface{}) map[string]interface{} {
+	b, _ := json.Marshal(m)
+	c := map[string]interface{}{}
+	_ = json.Unmarshal(b, &c)
+	return c
+}
+
+func toStrings(v interface{}) []string {
+	switch l := v.(type) {
+	case []string:
+		return l
+	case []interface{}:
+		s := make([]string, 0, len(l))
+		for _, i := range l {
+			if str, ok := i.(string); ok {
+				s = append(s, str)
+			}
+		}
+		return s
+	}
+	return nil
+}
+
+func containsString(list interface{}, value string) bool {
+	for _, s := range toStrings(list) {
+		if s == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAll(list interface{}, values []string) bool {
+	for _, v := range values {
+		if !containsString(list, v) {
+			return false
+		}
+	}
+	return true
+}
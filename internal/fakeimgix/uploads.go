@@ -0,0 +1,97 @@
+package fakeimgix
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Object is a file uploaded to the origin of a source
+type Object struct {
+	ContentType string
+	Content     []byte
+}
+
+// Object returns the file uploaded to the origin path of a source
+func (s *Server) Object(sourceId, originPath string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.objects[assetKey(sourceId, originPath)]
+	return o, ok
+}
+
+// Uploads returns the number of files uploaded to the origin path of a source
+func (s *Server) Uploads(sourceId, originPath string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.uploads[assetKey(sourceId, originPath)]
+}
+
+// RemoveObject deletes a file from the origin of a source and its asset, as if it was deleted from the storage directly
+func (s *Server) RemoveObject(sourceId, originPath string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := assetKey(sourceId, originPath)
+	delete(s.objects, key)
+	delete(s.assets, key)
+	for i, k := range s.assetOrder {
+		if k == key {
+			s.assetOrder = append(s.assetOrder[:i], s.assetOrder[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *Server) uploadObject(w http.ResponseWriter, req *http.Request, path string) {
+	sourceId, originPath := splitAssetPath(path)
+	src, ok := s.sources[sourceId]
+	if !ok {
+		writeErrors(w, http.StatusNotFound, apiError{Status: "404", Title: "not_found", Detail: "Source not found"})
+		return
+	}
+
+	if deploymentOf(src.attributes)["allows_upload"] != true {
+		writeErrors(w, http.StatusForbidden, apiError{
+			Status: "403",
+			Title:  "allows_upload",
+			Detail: "Source doesn't allow uploads",
+		})
+		return
+	}
+
+	if originPath == "/" {
+		writeErrors(w, http.StatusBadRequest, apiError{Status: "400", Title: "origin_path", Detail: "Origin path is required"})
+		return
+	}
+
+	content, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, apiError{Status: "400", Title: "invalid_body", Detail: err.Error()})
+		return
+	}
+
+	key := assetKey(sourceId, originPath)
+	contentType := req.Header.Get("Content-Type")
+	s.objects[key] = Object{ContentType: contentType, Content: content}
+	s.uploads[key]++
+	if s.skipUploadIndexing {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+
+	attributes := map[string]interface{}{}
+	if a, ok := s.assets[key]; ok {
+		attributes = a.attributes
+	}
+	attributes["content_type"] = contentType
+	attributes["etag"] = fmt.Sprintf("%x", md5.Sum(content))
+	attributes["file_size"] = len(content)
+	attributes["date_modified"] = s.now().Unix()
+
+	a := s.storeAsset(sourceId, originPath, attributes)
+	s.writeData(w, http.StatusCreated, a.resource())
+}
@@ -0,0 +1,247 @@
+// Package fakes3 implements a local stand-in of the S3 API used by the provider's tests of origin
+// preflight checks. Requests have to be path-style and signed with AWS Signature Version 4.
+package fakes3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Server is a fake S3 API listening on a local address
+type Server struct {
+	URL string
+
+	mu        sync.Mutex
+	http      *httptest.Server
+	accessKey string
+	secretKey string
+	buckets   map[string]map[string][]byte
+	denied    map[string]bool
+	requests  []string
+}
+
+// New starts a fake S3 API accepting requests signed with the given credentials. It has to be stopped with Close.
+func New(accessKey, secretKey string) *Server {
+	s := &Server{
+		accessKey: accessKey,
+		secretKey: secretKey,
+		buckets:   map[string]map[string][]byte{},
+		denied:    map[string]bool{},
+	}
+	s.http = httptest.NewServer(s)
+	s.URL = s.http.URL
+	return s
+}
+
+func (s *Server) Close() {
+	s.http.Close()
+}
+
+func (s *Server) CreateBucket(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.buckets[name]; !ok {
+		s.buckets[name] = map[string][]byte{}
+	}
+}
+
+// PutObject stores an object, creating the bucket when needed
+func (s *Server) PutObject(bucket, key string, body []byte) {
+	s.CreateBucket(bucket)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buckets[bucket][key] = body
+}
+
+// Deny makes requests needing the IAM action, such as s3:GetObject, fail with AccessDenied
+func (s *Server) Deny(action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.denied[action] = true
+}
+
+// Requests returns the received requests as "METHOD path" strings
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, req.Method+" "+req.URL.Path)
+
+	if code, message := s.authenticate(req); code != "" {
+		writeError(w, http.StatusForbidden, code, message)
+		return
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(req.URL.Path, "/"), "/", 2)
+	bucket, key := parts[0], ""
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+
+	objects, ok := s.buckets[bucket]
+	if bucket == "" || !ok {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "The specified bucket does not exist")
+		return
+	}
+
+	switch {
+	case key == "" && req.Method == http.MethodHead:
+		s.authorize(w, "s3:ListBucket", func() {
+			w.WriteHeader(http.StatusOK)
+		})
+	case key == "" && req.Method == http.MethodGet:
+		s.authorize(w, "s3:ListBucket", func() {
+			listObjects(w, bucket, objects, req.URL.Query())
+		})
+	case key != "" && (req.Method == http.MethodGet || req.Method == http.MethodHead):
+		s.authorize(w, "s3:GetObject", func() {
+			body, ok := objects[key]
+			if !ok {
+				writeError(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write(body)
+		})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+	}
+}
+
+func (s *Server) authorize(w http.ResponseWriter, action string, handle func()) {
+	if s.denied[action] {
+		writeError(w, http.StatusForbidden, "AccessDenied", "Access Denied")
+		return
+	}
+	handle()
+}
+
+// authenticate verifies the signature of the request, returning an S3 error code when it is invalid
+func (s *Server) authenticate(req *http.Request) (string, string) {
+	auth := strings.TrimPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	fields := map[string]string{}
+	for _, field := range strings.Split(auth, ",") {
+		kv := strings.SplitN(strings.TrimSpace(field), "=", 2)
+		if len(kv) == 2 {
+			fields[kv[0]] = kv[1]
+		}
+	}
+
+	credential := strings.SplitN(fields["Credential"], "/", 2)
+	if len(credential) != 2 || credential[0] != s.accessKey {
+		return "InvalidAccessKeyId", "The AWS Access Key Id you provided does not exist in our records."
+	}
+
+	scope := credential[1]
+	scopeParts := strings.Split(scope, "/")
+	if len(scopeParts) != 4 {
+		return "AuthorizationHeaderMalformed", "The authorization header is malformed."
+	}
+
+	var headers []string
+	for _, name := range strings.Split(fields["SignedHeaders"], ";") {
+		value := req.Header.Get(name)
+		if name == "host" {
+			value = req.Host
+		}
+		headers = append(headers, name+":"+strings.TrimSpace(value)+"\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		strings.Join(headers, ""),
+		fields["SignedHeaders"],
+		req.Header.Get("x-amz-content-sha256"),
+	}, "\n")
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", req.Header.Get("x-amz-date"), scope, hex.EncodeToString(hash[:])}, "\n")
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range scopeParts {
+		key = sign(key, part)
+	}
+
+	if !hmac.Equal([]byte(hex.EncodeToString(sign(key, stringToSign))), []byte(fields["Signature"])) {
+		return "SignatureDoesNotMatch", "The request signature we calculated does not match the signature you provided."
+	}
+	return "", ""
+}
+
+func listObjects(w http.ResponseWriter, bucket string, objects map[string][]byte, query url.Values) {
+	type content struct {
+		Key  string `xml:"Key"`
+		Size int    `xml:"Size"`
+	}
+	result := struct {
+		XMLName  xml.Name  `xml:"ListBucketResult"`
+		Name     string    `xml:"Name"`
+		Prefix   string    `xml:"Prefix"`
+		KeyCount int       `xml:"KeyCount"`
+		Contents []content `xml:"Contents"`
+	}{Name: bucket, Prefix: query.Get("prefix")}
+
+	var keys []string
+	for key := range objects {
+		if strings.HasPrefix(key, result.Prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		result.Contents = append(result.Contents, content{Key: key, Size: len(objects[key])})
+	}
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
+
+func canonicalQuery(query url.Values) string {
+	var parts []string
+	for k, values := range query {
+		for _, v := range values {
+			parts = append(parts, encode(k)+"="+encode(v))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "&")
+}
+
+func encode(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+func sign(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
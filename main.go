@@ -1,15 +1,26 @@
 package main
 
 import (
+	"context"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
+	"log"
 	"terraform-provider-imgix/imgix"
 )
 
 func main() {
+	shutdownTracing, err := imgix.SetupTracing(context.Background())
+	if err != nil {
+		log.Printf("[WARN] Error setting up OpenTelemetry tracing: %s", err.Error())
+	}
+
 	plugin.Serve(&plugin.ServeOpts{
 		ProviderFunc: func() *schema.Provider {
 			return imgix.Provider()
 		},
 	})
+
+	if err = shutdownTracing(context.Background()); err != nil {
+		log.Printf("[WARN] Error flushing OpenTelemetry spans: %s", err.Error())
+	}
 }